@@ -30,6 +30,20 @@ func (c *CosmosDBRepository) Create(ctx context.Context, media *common.Media) er
 	return nil
 }
 
+func (c *CosmosDBRepository) Update(ctx context.Context, media *common.Media) error {
+	b, err := json.Marshal(media)
+	if err != nil {
+		return err
+	}
+	pk := azcosmos.NewPartitionKeyString(media.ID)
+	_, err = c.db.UpsertItem(ctx, pk, b, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *CosmosDBRepository) Read(ctx context.Context, id string) (*common.Media, error) {
 	result, err := c.db.ReadItem(ctx, azcosmos.NewPartitionKeyString(id), id, nil)
 	if err != nil {
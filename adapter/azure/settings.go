@@ -0,0 +1,56 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"jnelle/discord-music-bot/common"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+)
+
+// settingsKind is the partition key shared by all guild settings.
+const settingsKind = "guild"
+
+type settingsItem struct {
+	common.GuildSettings
+	Kind string `json:"kind"`
+}
+
+type SettingsRepository struct {
+	db *azcosmos.ContainerClient
+}
+
+func NewSettingsRepository(db *azcosmos.ContainerClient) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+func (c *SettingsRepository) ListSettings(ctx context.Context) ([]*common.GuildSettings, error) {
+	pager := c.db.NewQueryItemsPager("SELECT * FROM c", azcosmos.NewPartitionKeyString(settingsKind), nil)
+
+	var res []*common.GuildSettings
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range page.Items {
+			var item settingsItem
+			if err := json.Unmarshal(b, &item); err != nil {
+				return nil, err
+			}
+			res = append(res, &item.GuildSettings)
+		}
+	}
+
+	return res, nil
+}
+
+func (c *SettingsRepository) UpdateSettings(ctx context.Context, settings *common.GuildSettings) error {
+	b, err := json.Marshal(settingsItem{GuildSettings: *settings, Kind: settingsKind})
+	if err != nil {
+		return err
+	}
+	_, err = c.db.UpsertItem(ctx, azcosmos.NewPartitionKeyString(settingsKind), b, nil)
+
+	return err
+}
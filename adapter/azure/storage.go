@@ -2,8 +2,10 @@ package azure
 
 import (
 	"context"
+	"jnelle/discord-music-bot/common"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 )
 
 type StorageRepository struct {
@@ -14,6 +16,14 @@ func NewStorageRepository(client *azblob.Client) *StorageRepository {
 	return &StorageRepository{client: client}
 }
 
+func (s *StorageRepository) CreateContainer(ctx context.Context, containerName string) error {
+	_, err := s.client.CreateContainer(ctx, containerName, nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
 func (s *StorageRepository) UploadFile(ctx context.Context, containerName string, filename string, body []byte) error {
 	_, err := s.client.UploadBuffer(ctx, containerName, filename, body, nil)
 	if err != nil {
@@ -35,3 +45,29 @@ func (s *StorageRepository) DeleteFile(ctx context.Context, containerName string
 	}
 	return nil
 }
+
+func (s *StorageRepository) ListFiles(ctx context.Context, containerName string) ([]*common.StoredFile, error) {
+	pager := s.client.NewListBlobsFlatPager(containerName, nil)
+
+	var files []*common.StoredFile
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Segment.BlobItems {
+			file := &common.StoredFile{Name: *blob.Name}
+			if blob.Properties != nil {
+				if blob.Properties.ContentLength != nil {
+					file.Size = *blob.Properties.ContentLength
+				}
+				if blob.Properties.LastModified != nil {
+					file.LastModified = *blob.Properties.LastModified
+				}
+			}
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
@@ -1,17 +1,109 @@
 package youtubedlp
 
+import (
+	"fmt"
+	"time"
+)
+
+// SourceType tells how a video got into the queue.
+type SourceType string
+
+const (
+	SourceSingle   SourceType = "single"
+	SourcePlaylist SourceType = "playlist"
+	SourceAutoplay SourceType = "autoplay"
+)
+
 type Video struct {
 	URL       string
 	Title     string
 	Thumbnail string
-	Length    string
-	ID        string
+	// Length is the formatted duration, empty for live streams
+	Length string
+	// DurationSeconds is the numeric duration all totals are built from
+	DurationSeconds int
+	ID              string
+	// Artist is the artist if known, otherwise the uploading channel
+	Artist        string
+	RequesterID   string
+	RequesterName string
+	EnqueuedAt    time.Time
+	Source        SourceType
+	Chapters      []SongChapters
+	// Live streams have no length and play until they end or get skipped
+	Live bool
+}
+
+// NewVideo creates the queue entry of a song, the requester and enqueue time
+// are set by the caller.
+func NewVideo(url string, song *Song, source SourceType) *Video {
+	video := &Video{
+		URL:       url,
+		Title:     song.Title,
+		Thumbnail: "https://i.ytimg.com/vi/" + song.ID + "/maxresdefault.jpg",
+		ID:        song.ID,
+		Artist:    song.ArtistName(),
+		Source:    source,
+		Chapters:  song.Chapters,
+		Live:      song.IsLiveStream(),
+	}
+	if !video.Live {
+		video.DurationSeconds = int(song.Duration)
+		video.Length = FormatDuration(song.Duration)
+	}
+
+	return video
+}
+
+func (d *Video) IsAutoplay() bool {
+	return d.Source == SourceAutoplay
+}
+
+func (s *Song) IsLiveStream() bool {
+	return s.IsLive || s.LiveStatus == "is_live"
+}
+
+// ArtistName returns the artist if yt-dlp found one, otherwise the channel.
+func (s *Song) ArtistName() string {
+	if artist, ok := s.Artist.(string); ok && artist != "" {
+		return artist
+	}
+	if s.Channel != "" {
+		return s.Channel
+	}
+	return s.Uploader
 }
 
 func (d *Video) GetShortURL() string {
 	return "https://youtu.be/" + d.ID
 }
 
+// Duration returns zero if the length is unknown.
+func (d *Video) Duration() time.Duration {
+	return time.Duration(d.DurationSeconds) * time.Second
+}
+
+// ChapterAt returns the index of the chapter at pos, -1 if there is none.
+func (d *Video) ChapterAt(pos time.Duration) int {
+	for i := len(d.Chapters) - 1; i >= 0; i-- {
+		if pos >= d.Chapters[i].Start() {
+			return i
+		}
+	}
+	return -1
+}
+
+// FormatDuration renders a duration in seconds the way yt-dlp formats duration_string.
+func FormatDuration(seconds float64) string {
+	total := int(seconds)
+	h, m, sec := total/3600, (total%3600)/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
 type PlaylistSong struct {
 	ID    string `json:"id"`
 	URL   string `json:"url"`
@@ -37,7 +129,7 @@ type Song struct {
 	ChannelFollowerCount int                    `json:"channel_follower_count"`
 	ChannelID            string                 `json:"channel_id"`
 	ChannelURL           string                 `json:"channel_url"`
-	Chapters             interface{}            `json:"chapters"`
+	Chapters             []SongChapters         `json:"chapters"`
 	CommentCount         interface{}            `json:"comment_count"`
 	Description          string                 `json:"description"`
 	DisplayID            string                 `json:"display_id"`
@@ -206,6 +298,14 @@ type SongChapters struct {
 	StartTime float64 `json:"start_time"`
 }
 
+func (c *SongChapters) Start() time.Duration {
+	return time.Duration(c.StartTime * float64(time.Second))
+}
+
+func (c *SongChapters) End() time.Duration {
+	return time.Duration(c.EndTime * float64(time.Second))
+}
+
 type SongThumbnails struct {
 	Height     int    `json:"height,omitempty"`
 	ID         string `json:"id"`
@@ -23,7 +23,8 @@ type YouTubeService interface {
 	SearchYoutube(ctx context.Context, query string) ([]*Song, error)
 	GetYoutubeData(ctx context.Context, videoURL string) (*Song, error)
 	GetPlaylistInfo(ctx context.Context, url string, shuffle bool) ([]*Song, error)
-	PlayVideo(ctx context.Context, url string) *exec.Cmd
+	GetRelatedVideos(ctx context.Context, videoID string) ([]*Song, error)
+	PlayVideo(ctx context.Context, url string, start time.Duration) *exec.Cmd
 }
 
 var (
@@ -88,7 +89,7 @@ func (y *YouTubeRepository) SearchYoutube(ctx context.Context, query string) ([]
 	slog.Info("[youtube.go]", slog.String("SearchYoutube finished query", query))
 
 	if err := ytdlp.Wait(); err != nil {
-		slog.Error("[youtube.go]", "SearchYoutube error on wait", "error", err)
+		slog.Error("[youtube.go]", "SearchYoutube error on wait", "error", slog.Any("err", err))
 		return nil, err
 	}
 
@@ -213,10 +214,16 @@ func (y *YouTubeRepository) GetPlaylistInfo(ctx context.Context, url string, shu
 	return playListSongs, nil
 }
 
-func (y *YouTubeRepository) PlayVideo(ctx context.Context, url string) *exec.Cmd {
-	return exec.CommandContext(ctx,
-		"yt-dlp",
-		"--format", "ba",
+// GetRelatedVideos resolves YouTube's mix playlist for the video.
+func (y *YouTubeRepository) GetRelatedVideos(ctx context.Context, videoID string) ([]*Song, error) {
+	return y.GetPlaylistInfo(ctx, "https://www.youtube.com/watch?v="+videoID+"&list=RD"+videoID, false)
+}
+
+// PlayVideo streams the audio of the video to stdout, starting at start.
+func (y *YouTubeRepository) PlayVideo(ctx context.Context, url string, start time.Duration) *exec.Cmd {
+	args := []string{
+		// live streams usually only offer combined formats
+		"--format", "ba/b",
 		url,
 		"--cache-dir", y.cacheDir,
 		"--proxy", y.proxy,
@@ -224,7 +231,13 @@ func (y *YouTubeRepository) PlayVideo(ctx context.Context, url string) *exec.Cmd
 		"--no-warnings",
 		"--no-progress",
 		"-o", "-",
-	)
+	}
+	if start > 0 {
+		// let yt-dlp request the section from the start instead of
+		// downloading and decoding everything before it
+		args = append(args, "--download-sections", fmt.Sprintf("*%.3f-inf", start.Seconds()))
+	}
+	return exec.CommandContext(ctx, "yt-dlp", args...)
 }
 
 func (y *YouTubeRepository) DownloadVideo(ctx context.Context, url string) *exec.Cmd {
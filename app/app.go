@@ -3,6 +3,7 @@ package app
 import (
 	"jnelle/discord-music-bot/adapter"
 	youtubedlp "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
 	"jnelle/discord-music-bot/internal/discord/bot"
 	"sync"
 )
@@ -12,8 +13,13 @@ type Application struct {
 	YTService youtubedlp.YouTubeService
 	Bot       *bot.Bot
 	Adapter   *adapter.Adapter
+	Cache     *playback.AudioCache
+	Loudness  *playback.Loudness
+	Settings  *playback.SettingsStorage
+	// VoiceReconnectAttempts is how often a player waits for a lost voice connection.
+	VoiceReconnectAttempts int
 }
 
-func New(yt *youtubedlp.YouTubeRepository, bot *bot.Bot, adapter *adapter.Adapter) *Application {
-	return &Application{YTService: yt, Bot: bot, Adapter: adapter}
+func New(yt *youtubedlp.YouTubeRepository, bot *bot.Bot, adapter *adapter.Adapter, cache *playback.AudioCache, loudness *playback.Loudness, settings *playback.SettingsStorage, voiceReconnectAttempts int) *Application {
+	return &Application{YTService: yt, Bot: bot, Adapter: adapter, Cache: cache, Loudness: loudness, Settings: settings, VoiceReconnectAttempts: voiceReconnectAttempts}
 }
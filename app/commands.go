@@ -17,7 +17,7 @@ type Command interface {
 func (a *Application) SetupCommands() error {
 	botUserID := a.Bot.Session.State.User.ID
 	commands := map[string]Command{
-		"play": play.NewCommand(a.Bot, a.YTService, &a.Wg, a.Adapter.DB, a.Adapter.Storage),
+		"play": play.NewCommand(a.Bot, a.YTService, &a.Wg, a.Adapter.DB, a.Adapter.Storage, a.Cache, a.Loudness, a.Settings, a.VoiceReconnectAttempts),
 	}
 
 	for name, cmd := range commands {
@@ -0,0 +1,31 @@
+package play
+
+import (
+	"jnelle/discord-music-bot/domain/playback"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (c *Command) handleAutoplay(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	enabled := intr.ApplicationCommandData().Options[0].BoolValue()
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps != nil && c.getPlayerInSameChannel(sesh, intr) == nil {
+		return
+	}
+
+	if _, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		s.Autoplay = enabled
+	}); !ok {
+		return
+	}
+	if ps != nil {
+		ps.SetAutoplay(enabled)
+	}
+
+	if enabled {
+		c.respond(sesh, intr, "Autoplay enabled. Related songs are queued when the queue runs dry.")
+		return
+	}
+	c.respond(sesh, intr, "Autoplay disabled.")
+}
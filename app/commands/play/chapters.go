@@ -0,0 +1,112 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/embed"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const interactionNoChaptersResponse string = "The current song has no chapters."
+
+func (c *Command) handleChapters(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps == nil || ps.Current() == nil {
+		format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		return
+	}
+	video := ps.Current()
+	if len(video.Chapters) == 0 {
+		format.DisplayInteractionError(sesh, intr, interactionNoChaptersResponse)
+		return
+	}
+
+	current := video.ChapterAt(ps.Position())
+
+	var sb strings.Builder
+	for i, chapter := range video.Chapters {
+		line := fmt.Sprintf("%d: `%s` %s", i+1, youtube.FormatDuration(chapter.StartTime), chapter.Title)
+		if i == current {
+			line = "**" + line + "**"
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	embed := embed.NewEmbed().
+		SetAuthor("Chapters").
+		SetTitle(video.Title).
+		SetUrl(video.GetShortURL()).
+		SetDescription(sb.String()).
+		MessageEmbed
+
+	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+	if err != nil {
+		c.logger.Error("failure responding to interaction", "error", err)
+		format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+	}
+}
+
+func (c *Command) handleChapter(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+	video := ps.Current()
+	if video == nil {
+		format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		return
+	}
+	if len(video.Chapters) == 0 {
+		format.DisplayInteractionError(sesh, intr, interactionNoChaptersResponse)
+		return
+	}
+
+	current := video.ChapterAt(ps.Position())
+	target := strings.ToLower(strings.TrimSpace(intr.ApplicationCommandData().Options[0].StringValue()))
+
+	var idx int
+	switch target {
+	case "next":
+		idx = current + 1
+	case "prev", "previous":
+		idx = current - 1
+		if idx < 0 {
+			idx = 0
+		}
+	default:
+		n, err := strconv.Atoi(target)
+		if err != nil {
+			format.DisplayInteractionError(sesh, intr, "Target must be `next`, `prev` or a chapter number.")
+			return
+		}
+		idx = n - 1
+	}
+	if idx < 0 || idx >= len(video.Chapters) {
+		format.DisplayInteractionError(sesh, intr, "There is no such chapter.")
+		return
+	}
+
+	chapter := video.Chapters[idx]
+	if err := ps.Seek(chapter.Start()); err != nil {
+		if errors.Is(err, playback.ErrPlaybackIsNotRunning) {
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+			return
+		}
+		c.logger.Error("failure seeking to chapter", "error", err)
+		format.DisplayInteractionError(sesh, intr, "Failure seeking. See the log for details.")
+		return
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Jumped to chapter %d: **%s**.", idx+1, chapter.Title))
+}
@@ -23,6 +23,11 @@ const (
 	interactionSameChannelResponse   string = "You must be in the same voice channel as the bot to use this command."
 	interactionNothingToSkipResponse string = "Nothing to skip."
 	interactionSkippedSongResponse   string = "Skipped current song."
+	interactionPlayerClosedResponse  string = "The bot is just leaving the channel, please try again."
+	interactionVoiceLostResponse     string = "Lost the connection to the voice channel and couldn't restore it. Stopping playback."
+
+	// aloneGracePeriod is how long the player stays paused once everyone left its channel.
+	aloneGracePeriod = 5 * time.Minute
 )
 
 var (
@@ -43,12 +48,18 @@ var (
 
 type Command struct {
 	playerStorage     *playback.PlayerStorage
+	settings          *playback.SettingsStorage
 	logger            *slog.Logger
 	bot               *bot.Bot
 	youTubeRepository youtube.YouTubeService
 	wg                *sync.WaitGroup
 	db                common.DBService
 	storage           common.StorageService
+	cache             *playback.AudioCache
+	loudness          *playback.Loudness
+	cooldowns         *cooldowns
+
+	voiceReconnectAttempts int
 }
 
 func NewCommand(
@@ -57,15 +68,25 @@ func NewCommand(
 	wg *sync.WaitGroup,
 	db common.DBService,
 	storage common.StorageService,
+	cache *playback.AudioCache,
+	loudness *playback.Loudness,
+	settings *playback.SettingsStorage,
+	voiceReconnectAttempts int,
 ) *Command {
 	return &Command{
 		playerStorage:     playback.NewManager(),
+		settings:          settings,
 		logger:            slog.Default(),
 		bot:               bot,
 		youTubeRepository: YouTubeRepository,
 		wg:                wg,
 		db:                db,
 		storage:           storage,
+		cache:             cache,
+		loudness:          loudness,
+		cooldowns:         newCooldowns(),
+
+		voiceReconnectAttempts: voiceReconnectAttempts,
 	}
 }
 
@@ -77,16 +98,63 @@ func (c *Command) Setup() error {
 		c.wg.Add(1)
 		go func() {
 			defer c.wg.Done()
+			if !c.authorize(sesh, intr) {
+				return
+			}
 			opt := intr.ApplicationCommandData()
 			switch opt.Name {
-			case "play":
+			case "play", "playnext":
 				c.handlePlay(sesh, intr)
 			case "skip":
 				c.handleSkip(sesh, intr)
 			case "queue":
 				c.handleQueue(sesh, intr)
-				// case "playlist":
-				// 	c.handlePlayPlaylist(sesh, intr)
+			case "playlist":
+				c.handlePlaylist(sesh, intr)
+			case "pause":
+				c.handlePause(sesh, intr)
+			case "resume":
+				c.handleResume(sesh, intr)
+			case "seek":
+				c.handleSeek(sesh, intr)
+			case "nowplaying":
+				c.handleNowPlaying(sesh, intr)
+			case "loop":
+				c.handleLoop(sesh, intr)
+			case "remove":
+				c.handleRemove(sesh, intr)
+			case "move":
+				c.handleMove(sesh, intr)
+			case "shuffle":
+				c.handleShuffle(sesh, intr)
+			case "clear":
+				c.handleClear(sesh, intr)
+			case "skipto":
+				c.handleSkipTo(sesh, intr)
+			case "volume":
+				c.handleVolume(sesh, intr)
+			case "filter":
+				c.handleFilter(sesh, intr)
+			case "autoplay":
+				c.handleAutoplay(sesh, intr)
+			case "previous":
+				c.handlePrevious(sesh, intr)
+			case "history":
+				c.handleHistory(sesh, intr)
+			case "chapters":
+				c.handleChapters(sesh, intr)
+			case "chapter":
+				c.handleChapter(sesh, intr)
+			case "linger":
+				c.handleLinger(sesh, intr)
+			case "limits":
+				c.handleLimits(sesh, intr)
+			case "fairqueue":
+				c.handleFairQueue(sesh, intr)
+			case "voteskip":
+				c.handleVoteSkipSettings(sesh, intr)
+			case "djrole":
+				c.handleDJRole(sesh, intr)
 			}
 		}()
 
@@ -101,6 +169,26 @@ func (c *Command) GetSignature() []*discordgo.ApplicationCommand {
 			Name:        "play",
 			Description: "Play a youtube video",
 			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:         "search",
+					Description:  "Youtube link or search query",
+					Type:         discordgo.ApplicationCommandOptionString,
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Name:        "position",
+					Description: "Position in the queue, 2 plays the song next",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](2.0),
+				},
+			},
+		},
+		{
+			Name:        "playnext",
+			Description: "Play a youtube video right after the current one",
+			Type:        discordgo.ChatApplicationCommand,
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Name:         "search",
@@ -115,12 +203,83 @@ func (c *Command) GetSignature() []*discordgo.ApplicationCommand {
 			Name:        "playlist",
 			Description: "Play a youtube video playlist",
 			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "url",
+					Description: "Youtube playlist link",
+					Type:        discordgo.ApplicationCommandOptionString,
+					Required:    true,
+				},
+				{
+					Name:        "shuffle",
+					Description: "Shuffle the playlist before adding it to the queue",
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+				},
+				{
+					Name:        "start",
+					Description: "Position in the playlist to start from",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](1.0),
+				},
+				{
+					Name:        "limit",
+					Description: "Maximum amount of songs to add",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](1.0),
+				},
+			},
 		},
 		{
 			Name:        "stop",
 			Description: "Stop audio playback",
 			Type:        discordgo.ChatApplicationCommand,
 		},
+		{
+			Name:        "pause",
+			Description: "Pause audio playback",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "resume",
+			Description: "Resume paused audio playback",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "seek",
+			Description: "Jump to a time in the current song",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "time",
+					Description: "Timestamp like 1:23:45 or 83s, or +30s/-30s relative to the current position",
+					Type:        discordgo.ApplicationCommandOptionString,
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "nowplaying",
+			Description: "Show the current song and its progress",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "loop",
+			Description: "Repeat the current song or the whole queue",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "mode",
+					Description: "Loop mode",
+					Type:        discordgo.ApplicationCommandOptionString,
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "track", Value: playback.LoopTrack.String()},
+						{Name: "queue", Value: playback.LoopQueue.String()},
+						{Name: "off", Value: playback.LoopOff.String()},
+					},
+				},
+			},
+		},
 		{
 			Name:        "skip",
 			Description: "Skip current song",
@@ -134,6 +293,230 @@ func (c *Command) GetSignature() []*discordgo.ApplicationCommand {
 				},
 			},
 		},
+		{
+			Name:        "remove",
+			Description: "Remove a song from the queue",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:         "position",
+					Description:  "Position of the song in the queue",
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Required:     true,
+					Autocomplete: true,
+					MinValue:     utils.ToPtr[float64](2.0),
+				},
+			},
+		},
+		{
+			Name:        "move",
+			Description: "Move a song to another position in the queue",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:         "from",
+					Description:  "Position of the song to move",
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Required:     true,
+					Autocomplete: true,
+					MinValue:     utils.ToPtr[float64](2.0),
+				},
+				{
+					Name:         "to",
+					Description:  "New position of the song",
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Required:     true,
+					Autocomplete: true,
+					MinValue:     utils.ToPtr[float64](2.0),
+				},
+			},
+		},
+		{
+			Name:        "shuffle",
+			Description: "Shuffle the upcoming songs",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "clear",
+			Description: "Remove all upcoming songs from the queue",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "skipto",
+			Description: "Skip to a song in the queue",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:         "position",
+					Description:  "Position of the song in the queue",
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Required:     true,
+					Autocomplete: true,
+					MinValue:     utils.ToPtr[float64](2.0),
+				},
+			},
+		},
+		{
+			Name:        "volume",
+			Description: "Set the playback volume of this server",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "percent",
+					Description: "Volume in percent, 100 is the original volume",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Required:    true,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    200,
+				},
+			},
+		},
+		filterSignature(),
+		{
+			Name:        "autoplay",
+			Description: "Keep playing related songs when the queue runs dry",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "enabled",
+					Description: "Enable or disable autoplay",
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "fairqueue",
+			Description: "Let upcoming songs take turns by requester",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "enabled",
+					Description: "Enable or disable the fair queue",
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "previous",
+			Description: "Play the previous song again",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "history",
+			Description: "View the recently played songs",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "amount",
+					Description: "Amount of songs to show",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](1.0),
+					MaxValue:    25,
+				},
+			},
+		},
+		{
+			Name:        "chapters",
+			Description: "List the chapters of the current song",
+			Type:        discordgo.ChatApplicationCommand,
+		},
+		{
+			Name:        "chapter",
+			Description: "Jump to a chapter of the current song",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "target",
+					Description: "`next`, `prev` or the chapter number",
+					Type:        discordgo.ApplicationCommandOptionString,
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "linger",
+			Description: "Set how long the bot stays in the channel after the queue ended",
+			Type:        discordgo.ChatApplicationCommand,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "minutes",
+					Description: "Minutes to stay, 0 leaves right away",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Required:    true,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    60,
+				},
+			},
+		},
+		{
+			Name:                     "limits",
+			Description:              "Show or set the queue limits per user",
+			Type:                     discordgo.ChatApplicationCommand,
+			DefaultMemberPermissions: utils.ToPtr[int64](discordgo.PermissionManageServer),
+			DMPermission:             utils.ToPtr(false),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "tracks",
+					Description: "Most tracks each user may have queued, 0 for unlimited",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    500,
+				},
+				{
+					Name:        "minutes",
+					Description: "Most queued minutes per user, 0 for unlimited",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    1440,
+				},
+				{
+					Name:        "cooldown",
+					Description: "Seconds each user has to wait between requests, 0 to disable",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    3600,
+				},
+			},
+		},
+		{
+			Name:                     "voteskip",
+			Description:              "Show or set when skipping needs a vote",
+			Type:                     discordgo.ChatApplicationCommand,
+			DefaultMemberPermissions: utils.ToPtr[int64](discordgo.PermissionManageServer),
+			DMPermission:             utils.ToPtr(false),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "listeners",
+					Description: "Listeners that can skip without a vote, more have to vote",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    99,
+				},
+				{
+					Name:        "percent",
+					Description: "Share of listeners that has to vote, 0 disables voting",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					MinValue:    utils.ToPtr[float64](0.0),
+					MaxValue:    100,
+				},
+			},
+		},
+		{
+			Name:                     "djrole",
+			Description:              "Set the role that may use all commands",
+			Type:                     discordgo.ChatApplicationCommand,
+			DefaultMemberPermissions: utils.ToPtr[int64](discordgo.PermissionManageServer),
+			DMPermission:             utils.ToPtr(false),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "role",
+					Description: "The DJ role, leave empty to allow everyone",
+					Type:        discordgo.ApplicationCommandOptionRole,
+				},
+			},
+		},
 		{
 			Name:        "queue",
 			Description: "View the current song queue",
@@ -150,49 +533,22 @@ func (c *Command) GetSignature() []*discordgo.ApplicationCommand {
 	}
 }
 
-// func (c *Command) handlePlayPlaylist(session *discordgo.Session, intr *discordgo.InteractionCreate) {
-// 	opt := intr.ApplicationCommandData()
-// 	queryString := opt.Options[0].StringValue()
-
-// 	log := c.logger.With("query", queryString)
-// 	url, err := url.ParseRequestURI(queryString)
-// 	if err != nil {
-// 		log.Error("error parsing url", "err", err)
-// 		format.DisplayInteractionError(session, intr, "Error parsing url!")
-// 		return
-// 	}
-// 	if !utils.ArrayContains[string](allowedHosts, url.Host) {
-// 		log.Error("error parsing url: incorrect domain " + url.Host)
-// 		format.DisplayInteractionError(session, intr, "Domain must be `youtube.com`, `youtu.be` or `www.youtube.com`")
-// 		return
-// 	}
-
-// 	playlistURL := url.String()
-// 	log.Info("requesting playlist data", "url", playlistURL)
-
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-
-// 	playlist, err := c.YouTubeRepository.GetPlaylistInfo(ctx, playlistURL, false)
-// 	if err != nil {
-// 		log.Error("error getting youtube data", "err", err)
-// 		format.DisplayInteractionError(session, intr, "Error getting video data from youtube. See the log for details.")
-// 		return
-// 	}
-// 	var player *playback.Player
-// 	if ps := c.playerStorage.Get(intr.GuildID); ps != nil {
-// 		log.Info("get stored player")
-// 		player = ps
-// 	}
-// }
-
 func (c *Command) handlePlay(session *discordgo.Session, intr *discordgo.InteractionCreate) {
 	if intr.Type == discordgo.InteractionApplicationCommandAutocomplete {
 		c.handlePlayAutocomplete(session, intr)
 		return
 	}
-	opt := intr.ApplicationCommandData()
-	queryString := opt.Options[0].StringValue()
+	opts := optionsMap(intr.ApplicationCommandData().Options)
+	queryString := opts["search"].StringValue()
+
+	// 0 appends the video to the queue
+	position := 0
+	if opt, ok := opts["position"]; ok {
+		position = int(opt.IntValue())
+	}
+	if intr.ApplicationCommandData().Name == "playnext" {
+		position = 2
+	}
 
 	log := c.logger.With("[command.go]", slog.String("query", queryString))
 
@@ -223,6 +579,17 @@ func (c *Command) handlePlay(session *discordgo.Session, intr *discordgo.Interac
 			return
 		}
 	}
+
+	if ps := c.playerStorage.Get(intr.GuildID); position > 0 && ps != nil && ps.FairQueue() {
+		format.DisplayInteractionError(session, intr, interactionFairQueueOrderResponse)
+		return
+	}
+
+	video := c.toYouTubeModel(videoURL, data, youtube.SourceSingle, intr.Member)
+	if !c.checkCooldown(session, intr) || !c.checkQuota(session, intr, 1, video.Duration()) {
+		return
+	}
+
 	err = session.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
@@ -231,47 +598,45 @@ func (c *Command) handlePlay(session *discordgo.Session, intr *discordgo.Interac
 		return
 	}
 
-	var player *playback.Player
-	if ps := c.playerStorage.Get(intr.GuildID); ps != nil {
-		log.Info("get stored player")
-		player = ps
-	} else {
-		p, err := c.createAndJoinVoiceChannelPlayer(log, session, intr)
-		if err != nil {
-			switch err {
-			case errUserNotInAnyChannel:
-				format.DisplayInteractionError(session, intr, "You must be in a voice channel to use this command.")
-			case errFailedJoinVoiceChannel:
-				format.DisplayInteractionError(session, intr, "Error joining voice channel.")
-			case errStartingPlayback:
-				format.DisplayInteractionError(session, intr, "Error starting playback.")
-
-			}
-		}
-		player = p
-	}
-
-	video := c.toYouTubeModel(videoURL, data.Title, data.Thumbnail, data.DurationString, data.ID)
-	if err := player.EnqueueVideo(video); err != nil {
-		log.Error("Failed to enqueue video", slog.String("error", err.Error()))
+	player := c.getOrCreatePlayer(log, session, intr)
+	if player == nil {
 		return
 	}
 
-	duration, err := time.ParseDuration(fmt.Sprintf("%vs", data.Duration))
+	var pos int
+	if position > 0 {
+		// positions are shown with the current video as 1
+		pos, err = player.InsertVideo(video, position-1)
+	} else {
+		pos, err = player.EnqueueVideo(video)
+	}
 	if err != nil {
-		log.Error("Duration doesnt exist", slog.String("error", err.Error()))
+		log.Error("Failed to enqueue video", slog.String("error", err.Error()))
+		if errors.Is(err, playback.ErrPlayerClosed) {
+			format.DisplayInteractionError(session, intr, interactionPlayerClosedResponse)
+			return
+		}
+		if errors.Is(err, playback.ErrFairQueueOrder) {
+			format.DisplayInteractionError(session, intr, interactionFairQueueOrderResponse)
+			return
+		}
+		format.DisplayInteractionError(session, intr, "Error adding the video to the queue.")
+		return
 	}
 
 	log.Info("added video to player", "video", video.Title)
+	c.startCooldown(intr)
 	utils.BackgroundTask(c.wg, func() error {
+		// ctx is cancelled as soon as the handler returns
+		ctx := context.Background()
 		media, err := c.db.Read(ctx, data.ID)
 		if err != nil && media == nil {
+			// BucketPath is set once the audio cache stored the audio
 			err = c.db.Create(ctx, &common.Media{
 				ID:             data.ID,
 				Title:          data.Title,
 				DurationString: data.DurationString,
 				Duration:       data.Duration,
-				BucketPath:     data.OriginalURL,
 			})
 			if err != nil {
 				slog.Error("[command.go]", slog.String("error", err.Error()))
@@ -288,8 +653,8 @@ func (c *Command) handlePlay(session *discordgo.Session, intr *discordgo.Interac
 		SetTitle(video.Title).
 		SetUrl(video.GetShortURL()).
 		SetThumbnail(video.Thumbnail).
-		SetDescription(video.Length).
-		SetFooter(fmt.Sprintf("Queue length: %d Queue duration: %s", player.Count(), duration.String()), "").
+		SetDescription(lengthLabel(video)).
+		SetFooter(fmt.Sprintf("Position in queue: %d Queue length: %d", pos+1, len(player.Queue())), "").
 		MessageEmbed
 
 	_, err = session.FollowupMessageCreate(intr.Interaction, false, &discordgo.WebhookParams{
@@ -301,8 +666,37 @@ func (c *Command) handlePlay(session *discordgo.Session, intr *discordgo.Interac
 	}
 }
 
+func (c *Command) getOrCreatePlayer(log *slog.Logger, session *discordgo.Session, intr *discordgo.InteractionCreate) *playback.Player {
+	if ps := c.playerStorage.Get(intr.GuildID); ps != nil {
+		log.Info("get stored player")
+		return ps
+	}
+
+	player, err := c.createAndJoinVoiceChannelPlayer(log, session, intr)
+	if err != nil {
+		switch err {
+		case errUserNotInAnyChannel:
+			format.DisplayInteractionError(session, intr, "You must be in a voice channel to use this command.")
+		case errFailedJoinVoiceChannel:
+			format.DisplayInteractionError(session, intr, "Error joining voice channel.")
+		case errStartingPlayback:
+			format.DisplayInteractionError(session, intr, "Error starting playback.")
+		}
+		return nil
+	}
+
+	return player
+}
+
 func (c *Command) setupPlayer(session *discordgo.Session, intr *discordgo.InteractionCreate, voice *discordgo.VoiceConnection, log *slog.Logger) *playback.Player {
-	player := playback.NewPlayer(voice, c.youTubeRepository)
+	player := playback.NewPlayer(voice, c.youTubeRepository, c.cache, c.loudness)
+	settings := c.settings.Get(intr.GuildID)
+	player.SetVolume(settings.Volume)
+	player.SetAutoplay(settings.Autoplay)
+	player.SetFairQueue(settings.FairQueue)
+	player.SetLingerTimeout(settings.LingerTimeout)
+	player.SetReconnectAttempts(c.voiceReconnectAttempts)
+	player.SetPersistWaitGroup(c.wg)
 	if err := c.playerStorage.Add(intr.GuildID, player); err != nil {
 		log.Error("error adding a new playback service", "guildId", intr.GuildID, "err", err)
 		return nil
@@ -311,34 +705,31 @@ func (c *Command) setupPlayer(session *discordgo.Session, intr *discordgo.Intera
 	// Run the service
 	go func(guildId string) {
 		playbackContext, playbackCancel := context.WithCancelCause(context.Background())
-		stopHandlerCancel := createStopHandler(session, playbackCancel, guildId)
-
-		// Setup service timeout ticker, in case bot is left alone in a channel
-		go func(channelId string) {
-			tick := time.NewTicker(time.Second * 30)
-			defer tick.Stop()
-			for {
-				select {
-				case <-playbackContext.Done():
-					return
-				case <-tick.C:
-					if last, err := c.isBotLastInVoiceChannel(session, guildId, channelId); err != nil {
-						log.Error("timeout ticker error", "error", err)
-						return
-					} else if last {
-						playbackCancel(playback.ErrCauseTimeout)
-						return
-					}
-
-				}
-			}
-		}(player.ChannelID())
+		stopHandlerCancel := c.createStopHandler(session, playbackCancel, guildId)
+
+		// Pause the playback in case bot is left alone, moved or disconnected
+		voiceStateHandlerCancel := createVoiceStateHandler(session, player, playbackCancel, guildId, log)
 
 		err := player.Run(playbackContext)
 		if err != nil && !errors.Is(playbackContext.Err(), context.Canceled) {
 			log.Error("playback error has occured", "err", err)
 		}
 		stopHandlerCancel()
+		voiceStateHandlerCancel()
+
+		if errors.Is(err, playback.ErrVoiceReconnectFailed) {
+			if _, err := session.ChannelMessageSend(intr.ChannelID, interactionVoiceLostResponse); err != nil {
+				log.Error("failure sending voice lost notice", "err", err)
+			}
+		}
+
+		// Run only returns without an error once the linger timeout expired
+		if linger := player.LingerTimeout(); err == nil && playbackContext.Err() == nil && linger > 0 {
+			msg := fmt.Sprintf("Left the voice channel after %s without anything to play.", linger.String())
+			if _, err := session.ChannelMessageSend(intr.ChannelID, msg); err != nil {
+				log.Error("failure sending linger notice", "err", err)
+			}
+		}
 
 		if err := player.Cleanup(); err != nil {
 			log.Error("failure to close player", "err", err)
@@ -353,9 +744,9 @@ func (c *Command) setupPlayer(session *discordgo.Session, intr *discordgo.Intera
 	return player
 }
 
-func createStopHandler(sesh *discordgo.Session, cancel context.CancelCauseFunc, guildID string) func() {
+func (c *Command) createStopHandler(sesh *discordgo.Session, cancel context.CancelCauseFunc, guildID string) func() {
 	return sesh.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if i.GuildID != guildID {
+		if i.GuildID != guildID || i.Type != discordgo.InteractionApplicationCommand {
 			return
 		}
 
@@ -363,6 +754,10 @@ func createStopHandler(sesh *discordgo.Session, cancel context.CancelCauseFunc,
 		if opt.Name != "stop" {
 			return
 		}
+		// the command handler responds to members that aren't allowed to stop
+		if c.accessDenied(i) != "" {
+			return
+		}
 
 		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -402,18 +797,27 @@ func (c *Command) handleSkip(sesh *discordgo.Session, intr *discordgo.Interactio
 		skipAmount = intr.ApplicationCommandData().Options[0].IntValue()
 	}
 
-	if ps := c.playerStorage.Get(guildID); ps != nil {
-		err := ps.Skip(int(skipAmount))
-		if errors.Is(err, playback.ErrSkipUnavailable) {
-			format.DisplayInteractionError(sesh, intr, interactionNothingToSkipResponse)
+	ps := c.playerStorage.Get(guildID)
+	if ps == nil {
+		format.DisplayInteractionError(sesh, intr, interactionNothingToSkipResponse)
+		return
+	}
+
+	// DJs can always skip, the requester only their own song
+	if current := ps.Current(); current != nil && !c.holdsDJRole(intr) && (skipAmount != 1 || current.RequesterID != userID) {
+		if listeners, needed := c.skipVotesNeeded(sesh, guildID, ps); needed > 0 {
+			c.handleSkipVote(sesh, intr, ps, int(skipAmount), listeners, needed)
 			return
 		}
-	} else {
+	}
+
+	err := ps.Skip(int(skipAmount))
+	if errors.Is(err, playback.ErrSkipUnavailable) || errors.Is(err, playback.ErrSkipNotPossible) {
 		format.DisplayInteractionError(sesh, intr, interactionNothingToSkipResponse)
 		return
 	}
 
-	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+	err = sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Content: interactionSkippedSongResponse,
@@ -425,12 +829,66 @@ func (c *Command) handleSkip(sesh *discordgo.Session, intr *discordgo.Interactio
 	}
 }
 
-func (c *Command) isUserAndBotInSameChannel(sesh *discordgo.Session, guildID string, userID string) error {
-	botUserID := sesh.State.User.ID
+// getPlayerInSameChannel returns the guild's player if the user shares the bot's
+// voice channel. Otherwise it responds with an error and returns nil.
+func (c *Command) getPlayerInSameChannel(sesh *discordgo.Session, intr *discordgo.InteractionCreate) *playback.Player {
+	if err := c.isUserAndBotInSameChannel(sesh, intr.GuildID, intr.Member.User.ID); err != nil {
+		switch {
+		case errors.Is(err, errUserNotInAnyChannel):
+			fallthrough
+		case errors.Is(err, errUserNotInBotsChannel):
+			format.DisplayInteractionError(sesh, intr, interactionSameChannelResponse)
+			return nil
+		case errors.Is(err, errBotIsNotInAnyChannel):
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+			return nil
+		}
+	}
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps == nil {
+		format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+	}
 
-	botChannelID, err := c.getUserChannelID(sesh, guildID, botUserID)
+	return ps
+}
+
+// updateSettings changes the guild's settings. If they can't be saved it
+// responds with an error and returns false.
+func (c *Command) updateSettings(sesh *discordgo.Session, intr *discordgo.InteractionCreate, fn func(s *playback.Settings)) (playback.Settings, bool) {
+	settings, err := c.settings.Update(intr.GuildID, fn)
+	if err != nil {
+		c.logger.Error("failure saving settings", "guildId", intr.GuildID, "error", err)
+		format.DisplayInteractionError(sesh, intr, "Failure saving the settings. See the log for details.")
+		return settings, false
+	}
+
+	return settings, true
+}
+
+func (c *Command) respond(sesh *discordgo.Session, intr *discordgo.InteractionCreate, content string) {
+	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+		},
+	})
 	if err != nil {
-		return errBotIsNotInAnyChannel
+		c.logger.Error("failure responding to interaction", "error", err)
+		format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+	}
+}
+
+func (c *Command) isUserAndBotInSameChannel(sesh *discordgo.Session, guildID string, userID string) error {
+	var botChannelID string
+	if ps := c.playerStorage.Get(guildID); ps != nil {
+		botChannelID = ps.ChannelID()
+	} else {
+		var err error
+		botChannelID, err = c.getUserChannelID(sesh, guildID, sesh.State.User.ID)
+		if err != nil {
+			return errBotIsNotInAnyChannel
+		}
 	}
 
 	channelID, err := c.getUserChannelID(sesh, guildID, userID)
@@ -476,29 +934,28 @@ func (c *Command) getUserChannelID(sesh *discordgo.Session, guildID string, user
 	return channelID, nil
 }
 
-func (c *Command) isBotLastInVoiceChannel(sesh *discordgo.Session, guildID string, channelID string) (bool, error) {
-	g, err := sesh.State.Guild(guildID)
-	if err != nil {
-		return false, fmt.Errorf("failure getting guild: %w", err)
-	}
+func (c *Command) toYouTubeModel(videoURL string, song *youtube.Song, source youtube.SourceType, requester *discordgo.Member) *youtube.Video {
+	video := youtube.NewVideo(videoURL, song, source)
+	video.RequesterID = requester.User.ID
+	video.RequesterName = memberName(requester)
 
-	for _, vs := range g.VoiceStates {
-		if vs.UserID != sesh.State.User.ID && vs.ChannelID == channelID {
-			return false, nil
-		}
-	}
+	return video
+}
 
-	return true, nil
+func memberName(member *discordgo.Member) string {
+	if member.Nick != "" {
+		return member.Nick
+	}
+	return member.User.Username
 }
 
-func (c *Command) toYouTubeModel(videoURL, title, thumbnail, length, ID string) *youtube.Video {
-	return &youtube.Video{
-		ID:        ID,
-		Title:     title,
-		Thumbnail: "https://i.ytimg.com/vi/" + ID + "/maxresdefault.jpg",
-		Length:    length,
-		URL:       videoURL,
+func optionsMap(opts []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	res := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(opts))
+	for _, opt := range opts {
+		res[opt.Name] = opt
 	}
+
+	return res
 }
 
 func (c *Command) createAndJoinVoiceChannelPlayer(log *slog.Logger, session *discordgo.Session, intr *discordgo.InteractionCreate) (*playback.Player, error) {
@@ -0,0 +1,33 @@
+package play
+
+import (
+	"jnelle/discord-music-bot/domain/playback"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const interactionFairQueueOrderResponse = "The fair queue decides the order of the songs. Disable it with /fairqueue to reorder them."
+
+func (c *Command) handleFairQueue(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	enabled := intr.ApplicationCommandData().Options[0].BoolValue()
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps != nil && c.getPlayerInSameChannel(sesh, intr) == nil {
+		return
+	}
+
+	if _, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		s.FairQueue = enabled
+	}); !ok {
+		return
+	}
+	if ps != nil {
+		ps.SetFairQueue(enabled)
+	}
+
+	if enabled {
+		c.respond(sesh, intr, "Fair queue enabled. Upcoming songs take turns by requester and can't be reordered manually.")
+		return
+	}
+	c.respond(sesh, intr, "Fair queue disabled. Upcoming songs play in the order they were added.")
+}
@@ -0,0 +1,118 @@
+package play
+
+import (
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"jnelle/discord-music-bot/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func filterSignature() *discordgo.ApplicationCommand {
+	presets := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	for _, name := range playback.FilterPresetNames() {
+		presets = append(presets, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+
+	gain := func(name, desc string) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Name:        name,
+			Description: desc,
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			MinValue:    utils.ToPtr[float64](-20.0),
+			MaxValue:    20,
+		}
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:        "filter",
+		Description: "Apply audio filters to the playback",
+		Type:        discordgo.ChatApplicationCommand,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "preset",
+				Description: "Apply a filter preset",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "name",
+						Description: "Name of the preset",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices:     presets,
+					},
+				},
+			},
+			{
+				Name:        "custom",
+				Description: "Apply custom speed, pitch and equalizer settings",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "speed",
+						Description: "Playback speed, 1 is normal",
+						Type:        discordgo.ApplicationCommandOptionNumber,
+						MinValue:    utils.ToPtr[float64](0.5),
+						MaxValue:    2,
+					},
+					{
+						Name:        "pitch",
+						Description: "Pitch, 1 is normal",
+						Type:        discordgo.ApplicationCommandOptionNumber,
+						MinValue:    utils.ToPtr[float64](0.5),
+						MaxValue:    2,
+					},
+					gain("bass", "Bass gain in dB"),
+					gain("mid", "Mid gain in dB"),
+					gain("treble", "Treble gain in dB"),
+				},
+			},
+			{
+				Name:        "off",
+				Description: "Remove all filters",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+		},
+	}
+}
+
+func (c *Command) handleFilter(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	sub := intr.ApplicationCommandData().Options[0]
+	opts := optionsMap(sub.Options)
+
+	var filter playback.Filter
+	switch sub.Name {
+	case "preset":
+		f, err := playback.FilterPreset(opts["name"].StringValue())
+		if err != nil {
+			format.DisplayInteractionError(sesh, intr, "Unknown filter preset.")
+			return
+		}
+		filter = f
+	case "custom":
+		if opt, ok := opts["speed"]; ok {
+			filter.Speed = opt.FloatValue()
+		}
+		if opt, ok := opts["pitch"]; ok {
+			filter.Pitch = opt.FloatValue()
+		}
+		if opt, ok := opts["bass"]; ok {
+			filter.Bass = opt.FloatValue()
+		}
+		if opt, ok := opts["mid"]; ok {
+			filter.Mid = opt.FloatValue()
+		}
+		if opt, ok := opts["treble"]; ok {
+			filter.Treble = opt.FloatValue()
+		}
+	}
+
+	ps.SetFilter(filter)
+	c.respond(sesh, intr, fmt.Sprintf("Filter set to `%s`.", filter))
+}
@@ -0,0 +1,84 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/embed"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const defaultHistoryLen int = 10
+
+func (c *Command) handlePrevious(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	video, err := ps.Previous()
+	if err != nil {
+		switch {
+		case errors.Is(err, playback.ErrNoPreviousVideo):
+			format.DisplayInteractionError(sesh, intr, "There is no previous song.")
+		case errors.Is(err, playback.ErrSkipNotPossible):
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		case errors.Is(err, playback.ErrPlayerClosed):
+			format.DisplayInteractionError(sesh, intr, interactionPlayerClosedResponse)
+		default:
+			c.logger.Error("failure playing previous song", "error", err)
+			format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+		}
+		return
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Playing **%s** again.", video.Title))
+}
+
+func (c *Command) handleHistory(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	limit := defaultHistoryLen
+	if opt := intr.ApplicationCommandData().Options; len(opt) > 0 {
+		limit = int(opt[0].IntValue())
+	}
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps == nil {
+		format.DisplayInteractionError(sesh, intr, "Nothing has been played yet.")
+		return
+	}
+	history := ps.History(limit)
+	if len(history) == 0 {
+		format.DisplayInteractionError(sesh, intr, "Nothing has been played yet.")
+		return
+	}
+
+	var sb strings.Builder
+	for i, video := range history {
+		title := video.Title
+		if len(title) > maxTitleLen {
+			title = title[:maxTitleLen-3] + "..."
+		}
+		fmt.Fprintf(&sb, "%d: [%s](%s) - %s\n", i+1, title, video.GetShortURL(), video.RequesterName)
+	}
+
+	embed := embed.NewEmbed().
+		SetAuthor("Recently played").
+		SetDescription(sb.String()).
+		SetTimestamp(time.Now().Format(time.RFC3339)).
+		MessageEmbed
+
+	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+	if err != nil {
+		c.logger.Error("failure responding to interaction", "error", err)
+		format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+	}
+}
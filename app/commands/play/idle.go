@@ -0,0 +1,141 @@
+package play
+
+import (
+	"context"
+	"errors"
+	"jnelle/discord-music-bot/domain/playback"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// idleWatcher pauses the player once the last listener left the bot's channel
+// and stops it if nobody rejoins within the grace period.
+type idleWatcher struct {
+	session *discordgo.Session
+	player  *playback.Player
+	cancel  context.CancelCauseFunc
+	guildID string
+	logger  *slog.Logger
+
+	mu         sync.Mutex
+	timer      *time.Timer
+	autoPaused bool
+}
+
+// createVoiceStateHandler starts watching the voice states of the guild and
+// returns a function removing the handler again. It keeps the player in sync
+// when the bot itself gets moved or disconnected.
+func createVoiceStateHandler(sesh *discordgo.Session, player *playback.Player, cancel context.CancelCauseFunc, guildID string, log *slog.Logger) func() {
+	w := &idleWatcher{
+		session: sesh,
+		player:  player,
+		cancel:  cancel,
+		guildID: guildID,
+		logger:  log,
+	}
+
+	removeHandler := sesh.AddHandler(func(s *discordgo.Session, vs *discordgo.VoiceStateUpdate) {
+		if vs.GuildID != guildID {
+			return
+		}
+
+		if vs.UserID == s.State.User.ID {
+			switch {
+			case vs.ChannelID == "" && player.Reconnecting():
+				log.Info("bot left the voice channel while reconnecting", "guildId", guildID)
+				return
+			case vs.ChannelID == "":
+				log.Info("bot was disconnected from the voice channel", "guildId", guildID)
+				cancel(playback.ErrCauseDisconnect)
+				return
+			case vs.ChannelID != player.ChannelID():
+				log.Info("bot was moved", "guildId", guildID, "from", player.ChannelID(), "to", vs.ChannelID)
+				player.SetChannelID(vs.ChannelID)
+			}
+		}
+		w.check()
+	})
+
+	return func() {
+		removeHandler()
+		w.stop()
+	}
+}
+
+// check compares the listeners in the bot's channel with the current state.
+func (w *idleWatcher) check() {
+	listeners, err := countListeners(w.session, w.guildID, w.player.ChannelID())
+	if err != nil {
+		w.logger.Error("failure counting listeners", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case listeners == 0 && w.timer == nil:
+		w.logger.Info("bot was left alone, pausing", "guildId", w.guildID, "grace", aloneGracePeriod.String())
+		if err := w.player.Pause(); err == nil {
+			w.autoPaused = true
+		}
+		w.timer = time.AfterFunc(aloneGracePeriod, func() {
+			w.logger.Info("nobody rejoined, stopping playback", "guildId", w.guildID)
+			w.cancel(playback.ErrCauseTimeout)
+		})
+	case listeners > 0 && w.timer != nil:
+		w.logger.Info("listener rejoined", "guildId", w.guildID)
+		w.timer.Stop()
+		w.timer = nil
+		if w.autoPaused {
+			if err := w.player.Resume(); err != nil && !errors.Is(err, playback.ErrNotPaused) {
+				w.logger.Error("failure resuming playback", "error", err)
+			}
+			w.autoPaused = false
+		}
+	}
+}
+
+func (w *idleWatcher) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// countListeners returns the number of users other than bots in a voice channel.
+func countListeners(sesh *discordgo.Session, guildID, channelID string) (int, error) {
+	g, err := sesh.State.Guild(guildID)
+	if err != nil {
+		return 0, errors.Join(errFailedGetGuild, err)
+	}
+
+	cnt := 0
+	for _, vs := range g.VoiceStates {
+		if vs.ChannelID != channelID || isBotUser(sesh, guildID, vs) {
+			continue
+		}
+		cnt++
+	}
+
+	return cnt, nil
+}
+
+func isBotUser(sesh *discordgo.Session, guildID string, vs *discordgo.VoiceState) bool {
+	if vs.UserID == sesh.State.User.ID {
+		return true
+	}
+	if vs.Member != nil && vs.Member.User != nil {
+		return vs.Member.User.Bot
+	}
+	if m, err := sesh.State.Member(guildID, vs.UserID); err == nil && m.User != nil {
+		return m.User.Bot
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package play
+
+import (
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (c *Command) handleLinger(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	timeout := time.Duration(intr.ApplicationCommandData().Options[0].IntValue()) * time.Minute
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps != nil && c.getPlayerInSameChannel(sesh, intr) == nil {
+		return
+	}
+
+	if _, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		s.LingerTimeout = timeout
+	}); !ok {
+		return
+	}
+	if ps != nil {
+		ps.SetLingerTimeout(timeout)
+	}
+
+	if timeout == 0 {
+		c.respond(sesh, intr, "The bot leaves the channel as soon as the queue ended.")
+		return
+	}
+	c.respond(sesh, intr, fmt.Sprintf("The bot stays in the channel for %s after the queue ended.", timeout.String()))
+}
@@ -0,0 +1,25 @@
+package play
+
+import (
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (c *Command) handleLoop(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	mode, err := playback.ParseLoopMode(intr.ApplicationCommandData().Options[0].StringValue())
+	if err != nil {
+		format.DisplayInteractionError(sesh, intr, "Loop mode must be `track`, `queue` or `off`.")
+		return
+	}
+
+	ps.SetLoopMode(mode)
+	c.respond(sesh, intr, fmt.Sprintf("Loop mode set to `%s`.", mode))
+}
@@ -0,0 +1,113 @@
+package play
+
+import (
+	"fmt"
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/embed"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"jnelle/discord-music-bot/utils"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	nowPlayingRefreshInterval = 5 * time.Second
+	progressBarLen            = 20
+)
+
+func (c *Command) handleNowPlaying(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps == nil || ps.Current() == nil {
+		format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		return
+	}
+	video := ps.Current()
+
+	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{nowPlayingEmbed(ps, video)},
+		},
+	})
+	if err != nil {
+		c.logger.Error("failure responding to interaction", "error", err)
+		format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+		return
+	}
+
+	// Interaction tokens expire after 15 minutes, so the message is edited
+	// through the channel instead of the interaction.
+	msg, err := sesh.InteractionResponse(intr.Interaction)
+	if err != nil {
+		c.logger.Error("failure fetching interaction response", "error", err)
+		return
+	}
+
+	utils.BackgroundTask(c.wg, func() error {
+		tick := time.NewTicker(nowPlayingRefreshInterval)
+		defer tick.Stop()
+		for range tick.C {
+			if c.playerStorage.Get(intr.GuildID) != ps || ps.Current() != video {
+				return nil
+			}
+
+			if _, err := sesh.ChannelMessageEditEmbed(msg.ChannelID, msg.ID, nowPlayingEmbed(ps, video)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func nowPlayingEmbed(ps *playback.Player, video *youtube.Video) *discordgo.MessageEmbed {
+	status := "Now playing"
+	if ps.IsPaused() {
+		status = "Paused"
+	}
+
+	pos := ps.Position()
+	e := embed.NewEmbed().
+		SetAuthor(status).
+		SetTitle(video.Title).
+		SetUrl(video.GetShortURL()).
+		SetThumbnail(video.Thumbnail).
+		SetDescription(progressBar(pos, video)).
+		AddInlineField("Requested by", video.RequesterName).
+		AddInlineField("Loop", ps.LoopMode().String())
+	if video.Artist != "" {
+		e.AddInlineField("Artist", video.Artist)
+	}
+	if idx := video.ChapterAt(pos); idx >= 0 {
+		e.AddInlineField("Chapter", video.Chapters[idx].Title)
+	}
+
+	return e.SetTimestamp(time.Now().Format(time.RFC3339)).MessageEmbed
+}
+
+func progressBar(elapsed time.Duration, video *youtube.Video) string {
+	if video.Live {
+		return fmt.Sprintf("%s `%s`", lengthLabel(video), youtube.FormatDuration(elapsed.Seconds()))
+	}
+
+	total := video.Duration()
+	if total <= 0 {
+		return fmt.Sprintf("`%s`", youtube.FormatDuration(elapsed.Seconds()))
+	}
+	if elapsed > total {
+		elapsed = total
+	}
+
+	pos := int(float64(progressBarLen) * elapsed.Seconds() / total.Seconds())
+	if pos >= progressBarLen {
+		pos = progressBarLen - 1
+	}
+
+	return fmt.Sprintf("`%s` %s🔘%s `%s`",
+		youtube.FormatDuration(elapsed.Seconds()),
+		strings.Repeat("▬", pos),
+		strings.Repeat("▬", progressBarLen-pos-1),
+		youtube.FormatDuration(total.Seconds()))
+}
@@ -0,0 +1,61 @@
+package play
+
+import (
+	"errors"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	interactionNothingPlayingResponse string = "Nothing is playing."
+	interactionAlreadyPausedResponse  string = "Playback is already paused."
+	interactionNotPausedResponse      string = "Playback isn't paused."
+	interactionPausedResponse         string = "Paused playback."
+	interactionResumedResponse        string = "Resumed playback."
+)
+
+func (c *Command) handlePause(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	if err := ps.Pause(); err != nil {
+		switch {
+		case errors.Is(err, playback.ErrPlaybackIsNotRunning):
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		case errors.Is(err, playback.ErrAlreadyPaused):
+			format.DisplayInteractionError(sesh, intr, interactionAlreadyPausedResponse)
+		default:
+			c.logger.Error("failure pausing playback", "error", err)
+			format.DisplayInteractionError(sesh, intr, "Failure pausing playback. See the log for details.")
+		}
+		return
+	}
+
+	c.respond(sesh, intr, interactionPausedResponse)
+}
+
+func (c *Command) handleResume(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	if err := ps.Resume(); err != nil {
+		switch {
+		case errors.Is(err, playback.ErrPlaybackIsNotRunning):
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+		case errors.Is(err, playback.ErrNotPaused):
+			format.DisplayInteractionError(sesh, intr, interactionNotPausedResponse)
+		default:
+			c.logger.Error("failure resuming playback", "error", err)
+			format.DisplayInteractionError(sesh, intr, "Failure resuming playback. See the log for details.")
+		}
+		return
+	}
+
+	c.respond(sesh, intr, interactionResumedResponse)
+}
@@ -0,0 +1,125 @@
+package play
+
+import (
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	interactionDJOnlyResponse       string = "Only DJs can use this command."
+	interactionOwnTrackResponse     string = "Only DJs can remove songs requested by someone else."
+	interactionManageServerResponse string = "You need the Manage Server permission to use this command."
+	interactionPositionResponse     string = "Only DJs can choose the position in the queue."
+)
+
+// access is what a member needs to use a command.
+type access int
+
+const (
+	accessEveryone access = iota
+	// accessAppend lets everyone add songs to the end of the queue
+	accessAppend
+	// accessOwnTrack lets everyone change the songs they requested themselves,
+	// the command checks the requester together with the change
+	accessOwnTrack
+	accessDJ
+	accessManageServer
+)
+
+// commandAccess lists the commands that don't need a DJ, all others do.
+var commandAccess = map[string]access{
+	"play":       accessAppend,
+	"playlist":   accessEveryone,
+	"queue":      accessEveryone,
+	"skip":       accessEveryone,
+	"nowplaying": accessEveryone,
+	"history":    accessEveryone,
+	"chapters":   accessEveryone,
+	"remove":     accessOwnTrack,
+	"limits":     accessManageServer,
+	"voteskip":   accessManageServer,
+	"djrole":     accessManageServer,
+}
+
+// authorize responds with an error and returns false if the member may not
+// use the command.
+func (c *Command) authorize(sesh *discordgo.Session, intr *discordgo.InteractionCreate) bool {
+	// autocompletion doesn't change anything
+	if intr.Type != discordgo.InteractionApplicationCommand {
+		return true
+	}
+
+	if msg := c.accessDenied(intr); msg != "" {
+		format.DisplayInteractionError(sesh, intr, msg)
+		return false
+	}
+	return true
+}
+
+// accessDenied returns why the member may not use the command, or an empty
+// string if they may.
+func (c *Command) accessDenied(intr *discordgo.InteractionCreate) string {
+	level, ok := commandAccess[intr.ApplicationCommandData().Name]
+	if !ok {
+		level = accessDJ
+	}
+
+	switch level {
+	case accessAppend:
+		if _, ok := optionsMap(intr.ApplicationCommandData().Options)["position"]; ok && !c.isDJ(intr) {
+			return interactionPositionResponse
+		}
+	case accessDJ:
+		if !c.isDJ(intr) {
+			return interactionDJOnlyResponse
+		}
+	case accessManageServer:
+		if !hasPermission(intr.Member, discordgo.PermissionManageServer) {
+			return interactionManageServerResponse
+		}
+	}
+
+	return ""
+}
+
+// isDJ reports whether the member may use the DJ commands. Without a DJ role
+// everyone may.
+func (c *Command) isDJ(intr *discordgo.InteractionCreate) bool {
+	return c.settings.Get(intr.GuildID).DJRoleID == "" || c.holdsDJRole(intr)
+}
+
+// holdsDJRole reports whether the member has the configured DJ role or may
+// manage channels. Unlike isDJ it is false for everyone else without a DJ role.
+func (c *Command) holdsDJRole(intr *discordgo.InteractionCreate) bool {
+	if hasPermission(intr.Member, discordgo.PermissionManageChannels) {
+		return true
+	}
+	roleID := c.settings.Get(intr.GuildID).DJRoleID
+	return roleID != "" && slices.Contains(intr.Member.Roles, roleID)
+}
+
+func hasPermission(member *discordgo.Member, permission int64) bool {
+	return member.Permissions&(permission|discordgo.PermissionAdministrator) != 0
+}
+
+func (c *Command) handleDJRole(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	var roleID string
+	if opt, ok := optionsMap(intr.ApplicationCommandData().Options)["role"]; ok {
+		roleID = opt.RoleValue(nil, "").ID
+	}
+
+	if _, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		s.DJRoleID = roleID
+	}); !ok {
+		return
+	}
+
+	if roleID == "" {
+		c.respond(sesh, intr, "DJ role removed. Everyone can use all commands again.")
+		return
+	}
+	c.respond(sesh, intr, "Members with <@&"+roleID+"> or the Manage Channels permission are DJs now. Everyone else can play songs, view the queue, vote to skip and remove their own songs.")
+}
@@ -20,8 +20,7 @@ func autocompleteResponse(choices []*discordgo.ApplicationCommandOptionChoice) *
 }
 
 func (c *Command) handlePlayAutocomplete(session *discordgo.Session, intr *discordgo.InteractionCreate) {
-	opt := intr.ApplicationCommandData()
-	queryString := opt.Options[0].StringValue()
+	queryString := optionsMap(intr.ApplicationCommandData().Options)["search"].StringValue()
 	log := c.logger.With("[play.go]", slog.Group("player/autocomplete", slog.String("query", queryString)))
 
 	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, 5)
@@ -0,0 +1,142 @@
+package play
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/embed"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (c *Command) handlePlaylist(session *discordgo.Session, intr *discordgo.InteractionCreate) {
+	opts := optionsMap(intr.ApplicationCommandData().Options)
+	queryString := opts["url"].StringValue()
+
+	log := c.logger.With("[playlist.go]", slog.String("query", queryString))
+
+	playlistURL, err := c.checkURL(log, queryString)
+	if err != nil {
+		format.DisplayInteractionError(session, intr, "Domain must be `youtube.com`, `youtu.be` and etc.")
+		return
+	}
+
+	if err := c.isUserAndBotInSameChannel(session, intr.GuildID, intr.Member.User.ID); err != nil {
+		switch {
+		case errors.Is(err, errUserNotInAnyChannel):
+			fallthrough
+		case errors.Is(err, errUserNotInBotsChannel):
+			format.DisplayInteractionError(session, intr, interactionSameChannelResponse)
+			return
+		}
+	}
+
+	if !c.checkCooldown(session, intr) {
+		return
+	}
+
+	shuffle := false
+	if opt, ok := opts["shuffle"]; ok {
+		shuffle = opt.BoolValue()
+	}
+
+	err = session.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Error("failure responding to interaction", "err", err)
+		return
+	}
+
+	log.Info("requesting playlist data", "url", playlistURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// start and limit select from the playlist's order, the selection gets shuffled
+	songs, err := c.youTubeRepository.GetPlaylistInfo(ctx, playlistURL, false)
+	if err != nil {
+		log.Error("error getting playlist data", "err", err)
+		format.DisplayInteractionError(session, intr, "Error getting playlist data from youtube. See the log for details.")
+		return
+	}
+
+	if opt, ok := opts["start"]; ok {
+		start := int(opt.IntValue()) - 1
+		if start >= len(songs) {
+			format.DisplayInteractionError(session, intr, fmt.Sprintf("The playlist only contains %d songs.", len(songs)))
+			return
+		}
+		songs = songs[start:]
+	}
+	if opt, ok := opts["limit"]; ok {
+		if limit := int(opt.IntValue()); limit < len(songs) {
+			songs = songs[:limit]
+		}
+	}
+
+	if shuffle {
+		rand.Shuffle(len(songs), func(i, j int) {
+			songs[i], songs[j] = songs[j], songs[i]
+		})
+	}
+
+	videos := make([]*youtube.Video, 0, len(songs))
+	var duration time.Duration
+	for _, song := range songs {
+		videoURL := "https://www.youtube.com/watch?v=" + song.ID
+		video := c.toYouTubeModel(videoURL, song, youtube.SourcePlaylist, intr.Member)
+		videos = append(videos, video)
+		duration += video.Duration()
+	}
+
+	if msg := c.quotaExceeded(intr, len(videos), duration); msg != "" {
+		// the deferred response is public, deleting it makes the error a new
+		// message only the requester sees
+		if err := session.InteractionResponseDelete(intr.Interaction); err != nil {
+			log.Error("failure deleting the deferred response", "err", err)
+		}
+		format.DisplayInteractionError(session, intr, msg)
+		return
+	}
+
+	player := c.getOrCreatePlayer(log, session, intr)
+	if player == nil {
+		return
+	}
+
+	if err := player.EnqueuePlaylist(videos); err != nil {
+		log.Error("failed to enqueue playlist", slog.String("error", err.Error()))
+		if errors.Is(err, playback.ErrPlayerClosed) {
+			format.DisplayInteractionError(session, intr, interactionPlayerClosedResponse)
+			return
+		}
+		format.DisplayInteractionError(session, intr, "Error adding the playlist to the queue.")
+		return
+	}
+
+	log.Info("added playlist to player", "count", len(videos))
+	c.startCooldown(intr)
+
+	embed := embed.NewEmbed().
+		SetAuthor("Added playlist to queue").
+		SetTitle(fmt.Sprintf("%d songs", len(videos))).
+		SetUrl(playlistURL).
+		SetThumbnail(videos[0].Thumbnail).
+		SetDescription(fmt.Sprintf("First song: [%s](%s)\nPlaylist duration: %s", videos[0].Title, videos[0].GetShortURL(), duration.String())).
+		SetFooter(fmt.Sprintf("Queue length: %d", len(player.Queue())), "").
+		MessageEmbed
+
+	_, err = session.FollowupMessageCreate(intr.Interaction, false, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Error("failure creating followup message to interaction", slog.String("err", err.Error()))
+	}
+}
@@ -3,10 +3,9 @@ package play
 import (
 	"fmt"
 	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
 	"jnelle/discord-music-bot/internal/discord/embed"
 	"jnelle/discord-music-bot/internal/discord/format"
-	"log/slog"
-	"strconv"
 	"strings"
 	"time"
 
@@ -25,8 +24,10 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 	guildID := intr.GuildID
 
 	var queue []*youtube.Video
+	loopMode := playback.LoopOff
 	if ps := c.playerStorage.Get(guildID); ps != nil {
 		queue = ps.Queue()
+		loopMode = ps.LoopMode()
 	}
 	if len(queue) == 0 {
 		format.DisplayInteractionError(sesh, intr, "There is nothing in the queue.")
@@ -41,7 +42,6 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 	if len(opt) > 0 {
 		totalNumOfFields = int(opt[0].IntValue())
 	}
-	totalLength := 0
 
 	currentVideo := queue[0]
 	embed := embed.NewEmbed().
@@ -49,8 +49,11 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 		SetTitle(currentVideo.Title).
 		SetThumbnail(currentVideo.Thumbnail).
 		SetUrl(currentVideo.GetShortURL()).
-		SetDescription(currentVideo.Length).
+		SetDescription(lengthLabel(currentVideo) + autoplayBadge(currentVideo)).
 		SetTimestamp(time.Now().Format(time.RFC3339))
+	if !currentVideo.IsAutoplay() {
+		embed.AddInlineField("Requested by", currentVideo.RequesterName)
+	}
 
 	fieldStart := 1
 	fieldEnd := 10
@@ -65,11 +68,11 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 			}
 
 			for x, video := range queue[fieldStart:fieldEnd] {
-				titleLen := len(video.Title)
-				if titleLen > maxTitleLen {
-					video.Title = video.Title[:maxTitleLen-3] + "..."
+				title := video.Title
+				if len(title) > maxTitleLen {
+					title = title[:maxTitleLen-3] + "..."
 				}
-				fmt.Fprintf(&sb, "%d: [%s](%s) - (%s)\n", fieldStart+x+1, video.Title, video.GetShortURL(), video.Length)
+				fmt.Fprintf(&sb, "%d: [%s](%s) - (%s)%s\n", fieldStart+x+1, title, video.GetShortURL(), lengthLabel(video), requesterLabel(video))
 			}
 
 			embed.AddField("", sb.String())
@@ -81,29 +84,15 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 		}
 	}
 
+	// live streams have no length and aren't part of the total
+	var duration time.Duration
 	for _, video := range queue {
-		if !strings.Contains(video.Length, ":") {
-			break
-		}
-		durations := strings.Split(video.Length, ":")
-		minutes, err := strconv.ParseInt(durations[0], 10, 64)
-		if err != nil {
-			minutes = 0
+		if !video.Live {
+			duration += video.Duration()
 		}
-		seconds, err := strconv.ParseInt(durations[1], 10, 64)
-		if err != nil {
-			seconds = 0
-		}
-		totalLength += int(minutes*60 + seconds)
-		slog.Debug("[queue.go]", slog.String("DURATION", fmt.Sprint(durations)))
-	}
-
-	duration, err := time.ParseDuration(fmt.Sprintf("%ds", totalLength))
-	if err != nil {
-		slog.Error("[queue.go]", slog.String("error", err.Error()))
 	}
-	embed.SetFooter(fmt.Sprintf("Total count: %d Total length: %s", queueLength, duration.String()), "")
-	err = sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
+	embed.SetFooter(fmt.Sprintf("Total count: %d Total length: %s Loop: %s", queueLength, duration.String(), loopMode), "")
+	err := sesh.InteractionRespond(intr.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
@@ -114,3 +103,25 @@ func (c *Command) handleQueue(sesh *discordgo.Session, intr *discordgo.Interacti
 		format.DisplayInteractionError(sesh, intr, "Failure responding to interaction. See the log for details.")
 	}
 }
+
+func lengthLabel(video *youtube.Video) string {
+	if video.Live {
+		return "🔴 LIVE"
+	}
+	return video.Length
+}
+
+func autoplayBadge(video *youtube.Video) string {
+	if video.IsAutoplay() {
+		return " [autoplay]"
+	}
+	return ""
+}
+
+// requesterLabel marks autoplay videos and names the requester of all others.
+func requesterLabel(video *youtube.Video) string {
+	if video.IsAutoplay() || video.RequesterName == "" {
+		return autoplayBadge(video)
+	}
+	return " - " + video.RequesterName
+}
@@ -0,0 +1,172 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxAutocompleteChoices             int = 25
+	interactionInvalidPositionResponse     = "There is no song at that position in the queue."
+)
+
+// Queue positions are shown the same way as in /queue: 1 is the current song,
+// 2 the next one. The player counts from the current song, hence the -1.
+
+func (c *Command) handleRemove(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	if intr.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		c.handleQueuePositionAutocomplete(sesh, intr)
+		return
+	}
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	pos := int(intr.ApplicationCommandData().Options[0].IntValue())
+	var video *youtube.Video
+	var err error
+	if c.isDJ(intr) {
+		video, err = ps.Remove(pos - 1)
+	} else {
+		video, err = ps.RemoveIfRequester(pos-1, intr.Member.User.ID)
+	}
+	if err != nil {
+		c.displayQueueEditError(sesh, intr, err)
+		return
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Removed **%s** from the queue.", video.Title))
+}
+
+func (c *Command) handleMove(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	if intr.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		c.handleQueuePositionAutocomplete(sesh, intr)
+		return
+	}
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	opts := optionsMap(intr.ApplicationCommandData().Options)
+	from := int(opts["from"].IntValue())
+	to := int(opts["to"].IntValue())
+	video, err := ps.Move(from-1, to-1)
+	if err != nil {
+		c.displayQueueEditError(sesh, intr, err)
+		return
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Moved **%s** to position %d.", video.Title, to))
+}
+
+func (c *Command) handleShuffle(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	if err := ps.Shuffle(); err != nil {
+		c.displayQueueEditError(sesh, intr, err)
+		return
+	}
+	c.respond(sesh, intr, "Shuffled the queue.")
+}
+
+func (c *Command) handleClear(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	cnt := ps.Clear()
+	c.respond(sesh, intr, fmt.Sprintf("Removed %d songs from the queue.", cnt))
+}
+
+func (c *Command) handleSkipTo(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	if intr.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		c.handleQueuePositionAutocomplete(sesh, intr)
+		return
+	}
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	pos := int(intr.ApplicationCommandData().Options[0].IntValue())
+	video, err := ps.SkipTo(pos - 1)
+	if err != nil {
+		c.displayQueueEditError(sesh, intr, err)
+		return
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Skipped to **%s**.", video.Title))
+}
+
+func (c *Command) displayQueueEditError(sesh *discordgo.Session, intr *discordgo.InteractionCreate, err error) {
+	switch {
+	case errors.Is(err, playback.ErrInvalidPosition):
+		format.DisplayInteractionError(sesh, intr, interactionInvalidPositionResponse)
+	case errors.Is(err, playback.ErrSkipNotPossible):
+		format.DisplayInteractionError(sesh, intr, interactionNothingToSkipResponse)
+	case errors.Is(err, playback.ErrFairQueueOrder):
+		format.DisplayInteractionError(sesh, intr, interactionFairQueueOrderResponse)
+	case errors.Is(err, playback.ErrNotRequester):
+		format.DisplayInteractionError(sesh, intr, interactionOwnTrackResponse)
+	default:
+		c.logger.Error("failure editing queue", "error", err)
+		format.DisplayInteractionError(sesh, intr, responseErrorMsg)
+	}
+}
+
+// handleQueuePositionAutocomplete suggests upcoming songs for the focused
+// position option, filtered by the typed number or part of the title.
+func (c *Command) handleQueuePositionAutocomplete(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	log := c.logger.With("[queueedit.go]", slog.String("command", intr.ApplicationCommandData().Name))
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxAutocompleteChoices)
+	defer func() {
+		if err := sesh.InteractionRespond(intr.Interaction, autocompleteResponse(choices)); err != nil {
+			log.Error("failed to respond", "error", err)
+		}
+	}()
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps == nil {
+		return
+	}
+
+	var query string
+	for _, opt := range intr.ApplicationCommandData().Options {
+		if opt.Focused {
+			query = strings.ToLower(fmt.Sprint(opt.Value))
+		}
+	}
+
+	queue := ps.Queue()
+	for i := 1; i < len(queue) && len(choices) < maxAutocompleteChoices; i++ {
+		pos := strconv.Itoa(i + 1)
+		title := queue[i].Title
+		if query != "" && !strings.HasPrefix(pos, query) && !strings.Contains(strings.ToLower(title), query) {
+			continue
+		}
+
+		name := pos + ": " + title
+		if len(name) > 100 {
+			name = name[:97] + "..."
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: i + 1,
+		})
+	}
+}
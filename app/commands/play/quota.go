@@ -0,0 +1,133 @@
+package play
+
+import (
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// cooldowns remembers until when each user of a guild has to wait before
+// requesting another track.
+type cooldowns struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func newCooldowns() *cooldowns {
+	return &cooldowns{
+		until: map[string]time.Time{},
+	}
+}
+
+// wait returns zero, or how long the user still has to wait if the cooldown
+// hasn't passed yet.
+func (cd *cooldowns) wait(guildID, userID string) time.Duration {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return max(time.Until(cd.until[guildID+"/"+userID]), 0)
+}
+
+// record starts the cooldown of the user and forgets the cooldowns that
+// already passed.
+func (cd *cooldowns) record(guildID, userID string, cooldown time.Duration) {
+	if cooldown <= 0 {
+		return
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	now := time.Now()
+	for key, until := range cd.until {
+		if !until.After(now) {
+			delete(cd.until, key)
+		}
+	}
+	cd.until[guildID+"/"+userID] = now.Add(cooldown)
+}
+
+// checkCooldown responds with an error and returns false if the user has to
+// wait before requesting another track.
+func (c *Command) checkCooldown(sesh *discordgo.Session, intr *discordgo.InteractionCreate) bool {
+	wait := c.cooldowns.wait(intr.GuildID, intr.Member.User.ID)
+	if wait == 0 {
+		return true
+	}
+
+	format.DisplayInteractionError(sesh, intr, fmt.Sprintf("Please wait %s before requesting another track.", wait.Round(time.Second).String()))
+	return false
+}
+
+// startCooldown starts the user's cooldown once their request was queued.
+func (c *Command) startCooldown(intr *discordgo.InteractionCreate) {
+	settings := c.settings.Get(intr.GuildID)
+	c.cooldowns.record(intr.GuildID, intr.Member.User.ID, settings.PlayCooldown)
+}
+
+// checkQuota responds with an error and returns false if adding tracks with
+// the given total length exceeds the user's queue limits.
+func (c *Command) checkQuota(sesh *discordgo.Session, intr *discordgo.InteractionCreate, tracks int, length time.Duration) bool {
+	if msg := c.quotaExceeded(intr, tracks, length); msg != "" {
+		format.DisplayInteractionError(sesh, intr, msg)
+		return false
+	}
+	return true
+}
+
+// quotaExceeded returns why adding tracks with the given total length exceeds
+// the user's queue limits, or an empty string if it doesn't.
+func (c *Command) quotaExceeded(intr *discordgo.InteractionCreate, tracks int, length time.Duration) string {
+	settings := c.settings.Get(intr.GuildID)
+	queued, queuedLength := 0, time.Duration(0)
+	if ps := c.playerStorage.Get(intr.GuildID); ps != nil {
+		queued, queuedLength = ps.RequesterUsage(intr.Member.User.ID)
+	}
+
+	if settings.MaxTracksPerUser > 0 && queued+tracks > settings.MaxTracksPerUser {
+		return fmt.Sprintf("You can have at most %d tracks in the queue, you already have %d.", settings.MaxTracksPerUser, queued)
+	}
+
+	maxLength := time.Duration(settings.MaxMinutesPerUser) * time.Minute
+	if maxLength > 0 && queuedLength+length > maxLength {
+		return fmt.Sprintf("You can have at most %d minutes in the queue, you already have %s.", settings.MaxMinutesPerUser, queuedLength.Round(time.Second).String())
+	}
+
+	return ""
+}
+
+func (c *Command) handleLimits(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	opts := optionsMap(intr.ApplicationCommandData().Options)
+
+	settings, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		if opt, ok := opts["tracks"]; ok {
+			s.MaxTracksPerUser = int(opt.IntValue())
+		}
+		if opt, ok := opts["minutes"]; ok {
+			s.MaxMinutesPerUser = int(opt.IntValue())
+		}
+		if opt, ok := opts["cooldown"]; ok {
+			s.PlayCooldown = time.Duration(opt.IntValue()) * time.Second
+		}
+	})
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Queue limits per user:\n")
+	sb.WriteString("Tracks: " + limitLabel(settings.MaxTracksPerUser, "") + "\n")
+	sb.WriteString("Minutes: " + limitLabel(settings.MaxMinutesPerUser, "") + "\n")
+	sb.WriteString("Cooldown: " + limitLabel(int(settings.PlayCooldown/time.Second), " seconds"))
+	c.respond(sesh, intr, sb.String())
+}
+
+func limitLabel(limit int, unit string) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d%s", limit, unit)
+}
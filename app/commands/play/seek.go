@@ -0,0 +1,100 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var errInvalidSeekTime = errors.New("invalid seek time")
+
+func (c *Command) handleSeek(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	ps := c.getPlayerInSameChannel(sesh, intr)
+	if ps == nil {
+		return
+	}
+
+	pos, relative, err := parseSeekTime(intr.ApplicationCommandData().Options[0].StringValue())
+	if err != nil {
+		format.DisplayInteractionError(sesh, intr, "Invalid time. Use `1:23:45`, `83s`, `+30s` or `-30s`.")
+		return
+	}
+
+	if relative {
+		err = ps.SeekBy(pos)
+	} else {
+		err = ps.Seek(pos)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, playback.ErrPlaybackIsNotRunning):
+			format.DisplayInteractionError(sesh, intr, interactionNothingPlayingResponse)
+			return
+		case errors.Is(err, playback.ErrSeekLiveStream):
+			format.DisplayInteractionError(sesh, intr, "Live streams can't be seeked.")
+			return
+		case errors.Is(err, playback.ErrSeekOutOfRange):
+			format.DisplayInteractionError(sesh, intr, "That's beyond the end of the song.")
+			return
+		}
+		c.logger.Error("failure seeking", "error", err)
+		format.DisplayInteractionError(sesh, intr, "Failure seeking. See the log for details.")
+		return
+	}
+
+	if relative {
+		c.respond(sesh, intr, fmt.Sprintf("Seeked by %s.", pos.String()))
+		return
+	}
+	c.respond(sesh, intr, fmt.Sprintf("Seeked to %s.", youtube.FormatDuration(pos.Seconds())))
+}
+
+// parseSeekTime parses `1:23:45`, `1:30`, `83`, `83s` and `1m30s`.
+// A leading `+` or `-` makes the time relative to the current position.
+func parseSeekTime(value string) (time.Duration, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, errInvalidSeekTime
+	}
+
+	relative := value[0] == '+' || value[0] == '-'
+	sign := time.Duration(1)
+	if relative {
+		if value[0] == '-' {
+			sign = -1
+		}
+		value = value[1:]
+	}
+
+	var pos time.Duration
+	if strings.Contains(value, ":") {
+		parts := strings.Split(value, ":")
+		if len(parts) > 3 {
+			return 0, false, errInvalidSeekTime
+		}
+		for _, part := range parts {
+			n, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return 0, false, errInvalidSeekTime
+			}
+			pos = pos*60 + time.Duration(n)*time.Second
+		}
+	} else if n, err := strconv.ParseUint(value, 10, 32); err == nil {
+		pos = time.Duration(n) * time.Second
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, false, errInvalidSeekTime
+		}
+		pos = d
+	}
+
+	return sign * pos, relative, nil
+}
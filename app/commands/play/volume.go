@@ -0,0 +1,34 @@
+package play
+
+import (
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (c *Command) handleVolume(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	volume := int(intr.ApplicationCommandData().Options[0].IntValue())
+
+	ps := c.playerStorage.Get(intr.GuildID)
+	if ps != nil && c.getPlayerInSameChannel(sesh, intr) == nil {
+		return
+	}
+
+	if _, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		s.Volume = volume
+	}); !ok {
+		return
+	}
+
+	// The encoder volume can't be changed while a track is playing.
+	if ps != nil {
+		ps.SetVolume(volume)
+		if ps.Current() != nil {
+			c.respond(sesh, intr, fmt.Sprintf("Volume set to %d%%. It applies from the next track.", volume))
+			return
+		}
+	}
+
+	c.respond(sesh, intr, fmt.Sprintf("Volume set to %d%%.", volume))
+}
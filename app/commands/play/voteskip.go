@@ -0,0 +1,76 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	"jnelle/discord-music-bot/domain/playback"
+	"jnelle/discord-music-bot/internal/discord/format"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// skipVotesNeeded returns the listeners in the bot's channel and how many of
+// them have to vote to skip, zero if no vote is needed.
+func (c *Command) skipVotesNeeded(sesh *discordgo.Session, guildID string, ps *playback.Player) (int, int) {
+	settings := c.settings.Get(guildID)
+	if settings.VoteSkipPercent <= 0 {
+		return 0, 0
+	}
+
+	listeners, err := countListeners(sesh, guildID, ps.ChannelID())
+	if err != nil {
+		c.logger.Error("failure counting listeners", "error", err)
+		return 0, 0
+	}
+	if listeners <= settings.VoteSkipListeners {
+		return listeners, 0
+	}
+
+	// round up, so half of 5 listeners needs 3 votes
+	needed := (listeners*settings.VoteSkipPercent + 99) / 100
+	return listeners, max(needed, 1)
+}
+
+func (c *Command) handleSkipVote(sesh *discordgo.Session, intr *discordgo.InteractionCreate, ps *playback.Player, amount, listeners, needed int) {
+	if amount > 1 {
+		format.DisplayInteractionError(sesh, intr, fmt.Sprintf("With %d listeners only the current song can be skipped by vote.", listeners))
+		return
+	}
+
+	votes, skipped, err := ps.VoteSkip(intr.Member.User.ID, needed)
+	if err != nil {
+		if !errors.Is(err, playback.ErrSkipNotPossible) {
+			c.logger.Error("failure voting to skip", "error", err)
+		}
+		format.DisplayInteractionError(sesh, intr, interactionNothingToSkipResponse)
+		return
+	}
+
+	if skipped {
+		c.respond(sesh, intr, fmt.Sprintf("Vote passed with %d/%d votes. Skipped current song.", votes, needed))
+		return
+	}
+	c.respond(sesh, intr, fmt.Sprintf("Voted to skip, %d/%d votes.", votes, needed))
+}
+
+func (c *Command) handleVoteSkipSettings(sesh *discordgo.Session, intr *discordgo.InteractionCreate) {
+	opts := optionsMap(intr.ApplicationCommandData().Options)
+
+	settings, ok := c.updateSettings(sesh, intr, func(s *playback.Settings) {
+		if opt, ok := opts["listeners"]; ok {
+			s.VoteSkipListeners = int(opt.IntValue())
+		}
+		if opt, ok := opts["percent"]; ok {
+			s.VoteSkipPercent = int(opt.IntValue())
+		}
+	})
+	if !ok {
+		return
+	}
+
+	if settings.VoteSkipPercent <= 0 {
+		c.respond(sesh, intr, "Vote-skip is disabled, every listener can skip right away.")
+		return
+	}
+	c.respond(sesh, intr, fmt.Sprintf("With more than %d listeners skipping needs votes from %d%% of them.", settings.VoteSkipListeners, settings.VoteSkipPercent))
+}
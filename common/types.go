@@ -2,17 +2,28 @@ package common
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 type DBService interface {
 	Create(ctx context.Context, media *Media) error
 	Read(ctx context.Context, id string) (*Media, error)
+	Update(ctx context.Context, media *Media) error
+}
+
+type SettingsService interface {
+	ListSettings(ctx context.Context) ([]*GuildSettings, error)
+	UpdateSettings(ctx context.Context, settings *GuildSettings) error
 }
 
 type StorageService interface {
+	// CreateContainer creates the container, it succeeds if it already exists.
+	CreateContainer(ctx context.Context, containerName string) error
 	UploadFile(ctx context.Context, containerName string, filename string, body []byte) error
 	DownloadFile(ctx context.Context, containerName string, filename string, buffer []byte) error
 	DeleteFile(ctx context.Context, containerName string, filename string) error
+	ListFiles(ctx context.Context, containerName string) ([]*StoredFile, error)
 }
 
 type Media struct {
@@ -21,4 +32,20 @@ type Media struct {
 	DurationString string  `json:"duration_string"`
 	Duration       float64 `json:"duration"`
 	BucketPath     string  `json:"bucket_path"`
+	Size           int64   `json:"size"`
+	// LoudnessGain in dB, nil until the loudness has been measured
+	LoudnessGain *float64 `json:"loudness_gain,omitempty"`
+}
+
+// GuildSettings holds the encoded settings of a guild.
+type GuildSettings struct {
+	// ID is the guild ID
+	ID       string          `json:"id"`
+	Settings json.RawMessage `json:"settings"`
+}
+
+type StoredFile struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
 }
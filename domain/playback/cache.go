@@ -0,0 +1,217 @@
+package playback
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/common"
+)
+
+// maxCachedFileSize caps the audio kept in memory for a single upload.
+const maxCachedFileSize = 64 << 20
+
+var ErrNotCached = errors.New("audio isn't cached")
+
+// AudioCache keeps the audio of played videos in blob storage. The media
+// records in the database point to the stored files, the least recently
+// used files are deleted once the cache grows beyond maxSize.
+type AudioCache struct {
+	db        common.DBService
+	storage   common.StorageService
+	container string
+	maxSize   int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	id   string
+	size int64
+}
+
+func NewAudioCache(db common.DBService, storage common.StorageService, container string, maxSize int64) *AudioCache {
+	return &AudioCache{
+		db:        db,
+		storage:   storage,
+		container: container,
+		maxSize:   maxSize,
+		lru:       list.New(),
+		entries:   map[string]*list.Element{},
+	}
+}
+
+// Get downloads the cached audio of the video.
+func (c *AudioCache) Get(ctx context.Context, id string) ([]byte, error) {
+	media, err := c.db.Read(ctx, id)
+	if err != nil || media == nil || media.BucketPath == "" || media.Size == 0 {
+		return nil, ErrNotCached
+	}
+
+	buf := make([]byte, media.Size)
+	if err := c.storage.DownloadFile(ctx, c.container, media.BucketPath, buf); err != nil {
+		return nil, err
+	}
+
+	c.touch(ctx, id, media.Size)
+
+	return buf, nil
+}
+
+// Put uploads the audio of the video and points its media record to it.
+func (c *AudioCache) Put(ctx context.Context, video *youtube.Video, data []byte) error {
+	filename := video.ID
+	if err := c.storage.UploadFile(ctx, c.container, filename, data); err != nil {
+		return err
+	}
+
+	media, err := c.db.Read(ctx, video.ID)
+	if err != nil || media == nil {
+		media = &common.Media{
+			ID:             video.ID,
+			Title:          video.Title,
+			DurationString: video.Length,
+			Duration:       video.Duration().Seconds(),
+		}
+	}
+	media.BucketPath = filename
+	media.Size = int64(len(data))
+	if err := c.db.Update(ctx, media); err != nil {
+		return err
+	}
+
+	c.touch(ctx, video.ID, media.Size)
+
+	return nil
+}
+
+// Load tracks the files uploaded by earlier runs, the least recently modified
+// count as the least recently used. Files beyond maxSize are evicted.
+func (c *AudioCache) Load(ctx context.Context) error {
+	files, err := c.storage.ListFiles(ctx, c.container)
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].LastModified.After(files[j].LastModified)
+	})
+
+	c.mu.Lock()
+	for _, file := range files {
+		if _, ok := c.entries[file.Name]; ok {
+			continue
+		}
+		// files are named after the video ID
+		c.entries[file.Name] = c.lru.PushBack(&cacheEntry{id: file.Name, size: file.Size})
+		c.size += file.Size
+	}
+	evicted := c.shrinkLocked()
+	size := c.size
+	c.mu.Unlock()
+
+	slog.Info("[cache.go]", slog.Int("files", len(files)), slog.Int64("size", size), slog.Int("evicted", len(evicted)))
+	c.evictAll(ctx, evicted)
+
+	return nil
+}
+
+// touch marks the file as recently used and evicts the least recently used
+// files while the cache is too big.
+func (c *AudioCache) touch(ctx context.Context, id string, size int64) {
+	c.mu.Lock()
+	if el, ok := c.entries[id]; ok {
+		c.size -= el.Value.(*cacheEntry).size
+		el.Value.(*cacheEntry).size = size
+		c.lru.MoveToFront(el)
+	} else {
+		c.entries[id] = c.lru.PushFront(&cacheEntry{id: id, size: size})
+	}
+	c.size += size
+	evicted := c.shrinkLocked()
+	c.mu.Unlock()
+
+	c.evictAll(ctx, evicted)
+}
+
+// shrinkLocked removes the least recently used entries while the cache is too
+// big and returns them for eviction.
+func (c *AudioCache) shrinkLocked() []*cacheEntry {
+	var evicted []*cacheEntry
+	for c.size > c.maxSize && c.lru.Len() > 1 {
+		entry := c.lru.Remove(c.lru.Back()).(*cacheEntry)
+		delete(c.entries, entry.id)
+		c.size -= entry.size
+		evicted = append(evicted, entry)
+	}
+	return evicted
+}
+
+func (c *AudioCache) evictAll(ctx context.Context, evicted []*cacheEntry) {
+	for _, entry := range evicted {
+		if err := c.evict(ctx, entry.id); err != nil {
+			slog.Error("[cache.go]", slog.String("message", "failed to evict "+entry.id), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (c *AudioCache) evict(ctx context.Context, id string) error {
+	// files of earlier runs may have lost their media record
+	media, err := c.db.Read(ctx, id)
+	if err != nil || media == nil {
+		return c.storage.DeleteFile(ctx, c.container, id)
+	}
+
+	filename := media.BucketPath
+	if filename == "" {
+		filename = id
+	}
+	if err := c.storage.DeleteFile(ctx, c.container, filename); err != nil {
+		return err
+	}
+
+	media.BucketPath = ""
+	media.Size = 0
+	return c.db.Update(ctx, media)
+}
+
+// captureBuffer collects the audio while it is streamed. It stops collecting
+// once the audio gets bigger than maxCachedFileSize and never fails a write,
+// so it can't interrupt the playback.
+type captureBuffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.overflow {
+		return len(p), nil
+	}
+	if b.buf.Len()+len(p) > maxCachedFileSize {
+		b.overflow = true
+		b.buf = bytes.Buffer{}
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// Bytes returns the captured audio, nil if it was too big.
+func (b *captureBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.overflow {
+		return nil
+	}
+	return b.buf.Bytes()
+}
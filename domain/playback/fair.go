@@ -0,0 +1,105 @@
+package playback
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+)
+
+// SetFairQueue enables or disables the fair queue. Enabling it interleaves the
+// upcoming videos by requester, disabling it restores the order they were
+// enqueued in. The current video isn't touched either way.
+//
+// While it is enabled the fair queue alone decides the order, so inserting at
+// a position, moving and shuffling fail with ErrFairQueueOrder. That way
+// disabling it can't throw away manual changes.
+func (s *Player) SetFairQueue(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fairQueue == enabled {
+		return
+	}
+	s.fairQueue = enabled
+	s.reorderUpcomingLocked()
+}
+
+func (s *Player) FairQueue() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fairQueue
+}
+
+// markEnqueued stamps the enqueue time and remembers the order the videos were
+// enqueued in, so it can be restored once the fair queue gets disabled.
+func (s *Player) markEnqueued(videos ...*youtube.Video) {
+	now := time.Now()
+	for _, v := range videos {
+		v.EnqueuedAt = now
+		s.enqueueSeq++
+		s.enqueued[v] = s.enqueueSeq
+	}
+}
+
+func (s *Player) forgetEnqueued(videos ...*youtube.Video) {
+	for _, v := range videos {
+		delete(s.enqueued, v)
+	}
+}
+
+func (s *Player) reorderUpcomingLocked() {
+	s.discardPrefetchedLocked()
+	start := max(s.queuePosition+1, 0)
+	if start >= len(s.queue) {
+		return
+	}
+	upcoming := s.queue[start:]
+
+	if !s.fairQueue {
+		sort.SliceStable(upcoming, func(i, j int) bool {
+			return s.enqueued[upcoming[i]] < s.enqueued[upcoming[j]]
+		})
+		return
+	}
+
+	current := ""
+	if s.queuePosition >= 0 && s.queuePosition < len(s.queue) {
+		current = s.queue[s.queuePosition].RequesterID
+	}
+	copy(upcoming, interleaveByRequester(upcoming, current))
+}
+
+// interleaveByRequester orders the videos round-robin by requester while each
+// requester's own order is kept. The requester of the current video takes the
+// last turn, autoplay videos come after all requested ones.
+func interleaveByRequester(videos []*youtube.Video, current string) []*youtube.Video {
+	groups := map[string][]*youtube.Video{}
+	requesters := []string{}
+	autoplay := []*youtube.Video{}
+	for _, v := range videos {
+		if v.IsAutoplay() {
+			autoplay = append(autoplay, v)
+			continue
+		}
+		if _, ok := groups[v.RequesterID]; !ok {
+			requesters = append(requesters, v.RequesterID)
+		}
+		groups[v.RequesterID] = append(groups[v.RequesterID], v)
+	}
+
+	if i := slices.Index(requesters, current); i >= 0 {
+		requesters = append(slices.Delete(requesters, i, i+1), current)
+	}
+
+	res := make([]*youtube.Video, 0, len(videos))
+	for round := 0; len(res) < len(videos)-len(autoplay); round++ {
+		for _, r := range requesters {
+			if round < len(groups[r]) {
+				res = append(res, groups[r][round])
+			}
+		}
+	}
+
+	return append(res, autoplay...)
+}
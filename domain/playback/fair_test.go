@@ -0,0 +1,81 @@
+package playback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// queueIDs joins the IDs of the upcoming videos.
+func queueIDs(p *Player) string {
+	ids := []string{}
+	for _, v := range p.upcomingLocked() {
+		ids = append(ids, v.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFairQueue(t *testing.T) {
+	p := NewPlayer(&discordgo.VoiceConnection{}, nil, nil, nil)
+	videos := []*youtube.Video{
+		{ID: "a1", RequesterID: "a"},
+		{ID: "a2", RequesterID: "a"},
+		{ID: "a3", RequesterID: "a"},
+		{ID: "b1", RequesterID: "b"},
+		{ID: "c1", RequesterID: "c"},
+		{ID: "b2", RequesterID: "b"},
+	}
+	if err := p.EnqueuePlaylist(videos); err != nil {
+		t.Fatal(err)
+	}
+
+	p.SetFairQueue(true)
+	if got, want := queueIDs(p), "a1,b1,c1,a2,b2,a3"; got != want {
+		t.Errorf("fair order = %s, want %s", got, want)
+	}
+
+	if _, err := p.Move(1, 2); !errors.Is(err, ErrFairQueueOrder) {
+		t.Errorf("Move() error = %v, want %v", err, ErrFairQueueOrder)
+	}
+	if err := p.Shuffle(); !errors.Is(err, ErrFairQueueOrder) {
+		t.Errorf("Shuffle() error = %v, want %v", err, ErrFairQueueOrder)
+	}
+	if _, err := p.InsertVideo(&youtube.Video{ID: "c2", RequesterID: "c"}, 1); !errors.Is(err, ErrFairQueueOrder) {
+		t.Errorf("InsertVideo() error = %v, want %v", err, ErrFairQueueOrder)
+	}
+
+	if _, err := p.EnqueueVideo(&youtube.Video{ID: "c2", RequesterID: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := queueIDs(p), "a1,b1,c1,a2,b2,c2,a3"; got != want {
+		t.Errorf("fair order after enqueue = %s, want %s", got, want)
+	}
+
+	p.SetFairQueue(false)
+	if got, want := queueIDs(p), "a1,a2,a3,b1,c1,b2,c2"; got != want {
+		t.Errorf("order after disabling = %s, want %s", got, want)
+	}
+}
+
+func TestFairQueueCurrentRequesterLast(t *testing.T) {
+	p := NewPlayer(&discordgo.VoiceConnection{}, nil, nil, nil)
+	videos := []*youtube.Video{
+		{ID: "a1", RequesterID: "a"},
+		{ID: "b1", RequesterID: "b"},
+		{ID: "a2", RequesterID: "a"},
+		{ID: "c1", RequesterID: "c"},
+	}
+	if err := p.EnqueuePlaylist(videos); err != nil {
+		t.Fatal(err)
+	}
+	p.queuePosition = 0
+
+	p.SetFairQueue(true)
+	if got, want := queueIDs(p), "b1,c1,a2"; got != want {
+		t.Errorf("fair order = %s, want %s", got, want)
+	}
+}
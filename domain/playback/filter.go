@@ -0,0 +1,104 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Filter describes the ffmpeg audio filters applied to every encoding session.
+// Zero values mean the property is left untouched.
+type Filter struct {
+	Name string
+
+	Speed float64
+	Pitch float64
+
+	// Equalizer gains in dB
+	Bass   float64
+	Mid    float64
+	Treble float64
+
+	// Extra is appended to the filter chain as is
+	Extra string
+}
+
+var ErrUnknownFilterPreset = errors.New("unknown filter preset")
+
+var filterPresets = map[string]Filter{
+	"bassboost": {Bass: 10},
+	"nightcore": {Speed: 1.25, Pitch: 1.25},
+	"vaporwave": {Speed: 0.8, Pitch: 0.8},
+	"8d":        {Extra: "apulsator=hz=0.125"},
+	"karaoke":   {Extra: "stereotools=mlev=0.03"},
+}
+
+func FilterPresetNames() []string {
+	return []string{"bassboost", "nightcore", "vaporwave", "8d", "karaoke"}
+}
+
+func FilterPreset(name string) (Filter, error) {
+	f, ok := filterPresets[name]
+	if !ok {
+		return Filter{}, ErrUnknownFilterPreset
+	}
+	f.Name = name
+
+	return f, nil
+}
+
+func (f Filter) IsZero() bool {
+	return f == Filter{}
+}
+
+func (f Filter) String() string {
+	if f.IsZero() {
+		return "off"
+	}
+	if f.Name != "" {
+		return f.Name
+	}
+	return "custom"
+}
+
+// Tempo returns how much faster than the source the filtered audio plays. The
+// pitch shift keeps the tempo, only Speed changes it.
+func (f Filter) Tempo() float64 {
+	if f.Speed == 0 {
+		return 1
+	}
+	return f.Speed
+}
+
+// FFmpeg returns the filter chain for ffmpeg's -af argument.
+func (f Filter) FFmpeg() string {
+	var filters []string
+
+	// Changing the sample rate shifts pitch and speed, atempo restores the speed.
+	if f.Pitch != 0 && f.Pitch != 1 {
+		filters = append(filters,
+			"aresample=48000",
+			fmt.Sprintf("asetrate=%d", int(48000*f.Pitch)),
+			"aresample=48000",
+			fmt.Sprintf("atempo=%.4f", 1/f.Pitch),
+		)
+	}
+	if f.Speed != 0 && f.Speed != 1 {
+		filters = append(filters, fmt.Sprintf("atempo=%.4f", f.Speed))
+	}
+
+	for _, band := range []struct {
+		freq int
+		gain float64
+	}{{100, f.Bass}, {1000, f.Mid}, {8000, f.Treble}} {
+		if band.gain != 0 {
+			filters = append(filters, fmt.Sprintf("equalizer=f=%d:t=q:w=1:g=%.1f", band.freq, band.gain))
+		}
+	}
+
+	if f.Extra != "" {
+		filters = append(filters, f.Extra)
+	}
+
+	return strings.Join(filters, ",")
+}
@@ -0,0 +1,36 @@
+package playback
+
+import "errors"
+
+type LoopMode int
+
+const (
+	LoopOff LoopMode = iota
+	LoopTrack
+	LoopQueue
+)
+
+var ErrUnknownLoopMode = errors.New("unknown loop mode")
+
+func (m LoopMode) String() string {
+	switch m {
+	case LoopTrack:
+		return "track"
+	case LoopQueue:
+		return "queue"
+	default:
+		return "off"
+	}
+}
+
+func ParseLoopMode(mode string) (LoopMode, error) {
+	switch mode {
+	case "off":
+		return LoopOff, nil
+	case "track":
+		return LoopTrack, nil
+	case "queue":
+		return LoopQueue, nil
+	}
+	return LoopOff, ErrUnknownLoopMode
+}
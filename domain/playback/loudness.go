@@ -0,0 +1,105 @@
+package playback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/common"
+)
+
+const (
+	// targetLoudness is the integrated loudness in LUFS every track is adjusted to
+	targetLoudness = -16.0
+	maxGain        = 20.0
+
+	// dynamicNormalizer is used until a track's loudness has been measured
+	dynamicNormalizer = "dynaudnorm"
+)
+
+var (
+	ErrLoudnessNotMeasured = errors.New("loudness couldn't be measured")
+
+	reIntegratedLoudness = regexp.MustCompile(`I:\s+(-?[\d.]+) LUFS`)
+)
+
+// Loudness measures the integrated loudness of tracks with ffmpeg's EBU R128
+// filter and stores the gain needed to reach targetLoudness in the media records.
+type Loudness struct {
+	db common.DBService
+}
+
+func NewLoudness(db common.DBService) *Loudness {
+	return &Loudness{db: db}
+}
+
+// Gain returns the stored gain in dB and whether the video has been measured.
+func (l *Loudness) Gain(ctx context.Context, id string) (float64, bool) {
+	media, err := l.db.Read(ctx, id)
+	if err != nil || media == nil || media.LoudnessGain == nil {
+		return 0, false
+	}
+	return *media.LoudnessGain, true
+}
+
+// Filter returns the ffmpeg filter that normalizes the video.
+func (l *Loudness) Filter(ctx context.Context, id string) (string, bool) {
+	gain, ok := l.Gain(ctx, id)
+	if !ok {
+		return dynamicNormalizer, false
+	}
+	return fmt.Sprintf("volume=%.2fdB", gain), true
+}
+
+// Measure analyzes the audio and stores the gain in the video's media record.
+func (l *Loudness) Measure(ctx context.Context, video *youtube.Video, data []byte) (float64, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-hide_banner",
+		"-nostats",
+		"-i", "pipe:0",
+		"-af", "ebur128=framelog=quiet",
+		"-f", "null",
+		"-",
+	)
+	cmd.Stdin = bytes.NewReader(data)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, err
+	}
+
+	// the summary is printed last
+	matches := reIntegratedLoudness.FindAllSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, ErrLoudnessNotMeasured
+	}
+	integrated, err := strconv.ParseFloat(string(matches[len(matches)-1][1]), 64)
+	if err != nil {
+		return 0, errors.Join(ErrLoudnessNotMeasured, err)
+	}
+
+	gain := targetLoudness - integrated
+	if gain > maxGain {
+		gain = maxGain
+	}
+	if gain < -maxGain {
+		gain = -maxGain
+	}
+
+	media, err := l.db.Read(ctx, video.ID)
+	if err != nil || media == nil {
+		media = &common.Media{
+			ID:             video.ID,
+			Title:          video.Title,
+			DurationString: video.Length,
+			Duration:       video.Duration().Seconds(),
+		}
+	}
+	media.LoudnessGain = &gain
+
+	return gain, l.db.Update(ctx, media)
+}
@@ -1,16 +1,18 @@
 package playback
 
 import (
-	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
-	"os/exec"
+	"math/rand"
+	"slices"
 	"sync"
 	"time"
 
 	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/utils"
 
 	"github.com/ClintonCollins/dca"
 	"github.com/bwmarrin/discordgo"
@@ -20,45 +22,168 @@ var (
 	ErrCauseStop              = errors.New("playback stopped")
 	ErrCauseTimeout           = errors.New("playback timed out")
 	ErrCauseSkip              = errors.New("playback skipped")
+	ErrCauseSeek              = errors.New("playback seeked")
+	ErrCauseDisconnect        = errors.New("bot was disconnected")
+	ErrCauseVoiceLost         = errors.New("voice connection lost")
+	ErrVoiceReconnectFailed   = errors.New("voice connection couldn't be restored")
 	ErrSkipUnavailable        = errors.New("queue is empty")
 	ErrSkipNotPossible        = errors.New("nothing to skip")
 	ErrPlayerIsAlreadyRunning = errors.New("player is already running")
 	ErrPlaybackIsNotRunning   = errors.New("playback service isn't running")
+	ErrAlreadyPaused          = errors.New("playback is already paused")
+	ErrNotPaused              = errors.New("playback isn't paused")
+	ErrInvalidPosition        = errors.New("invalid queue position")
+	ErrNoPreviousVideo        = errors.New("no previous video")
+	ErrSeekLiveStream         = errors.New("can't seek in live streams")
+	ErrSeekOutOfRange         = errors.New("seek position is beyond the end of the track")
+	ErrPlayerClosed           = errors.New("player is closed")
+	ErrFairQueueOrder         = errors.New("the fair queue decides the order")
+	ErrNotRequester           = errors.New("video was requested by someone else")
+)
+
+const (
+	// autoplayBatch is how many related videos autoplay queues at once.
+	autoplayBatch = 3
+	// maxHistory is how many finished videos are kept in the queue.
+	maxHistory = 50
+	// prefetchLead is how long before the end of a track the next one is opened.
+	prefetchLead = 10 * time.Second
+	// liveReconnectAttempts is how often a dropped live stream is reopened.
+	liveReconnectAttempts = 3
+	liveReconnectDelay    = 2 * time.Second
+	// DefaultVoiceReconnectAttempts is how often the player waits for a lost
+	// voice connection to come back, voiceReconnectWait each.
+	DefaultVoiceReconnectAttempts = 5
+	voiceReconnectWait            = 10 * time.Second
 )
 
 type Player struct {
 	vc *discordgo.VoiceConnection
 
 	skipFunc context.CancelCauseFunc
+	stream   *dca.StreamingSession
+
+	// prefetched is the next video's track, opened shortly before the
+	// current one ends. prefetching is set while it is being opened.
+	prefetched  *track
+	prefetching bool
+
+	// startOffset is where the current stream started within the track,
+	// seekTo is where the next stream of the same track has to start.
+	startOffset time.Duration
+	seekTo      time.Duration
+	// streamTempo is the speed factor of the stream's filter
+	streamTempo float64
+
+	paused bool
 
 	logger *slog.Logger
 	queue  []*youtube.Video
 
 	queuePosition int
+	loopMode      LoopMode
+	volume        int
+	filter        Filter
+	autoplay      bool
+	lingerTimeout time.Duration
+	closed        bool
+	channelID     string
+	fairQueue     bool
+	enqueued      map[*youtube.Video]uint64
+	enqueueSeq    uint64
+	skipVotes     map[string]struct{}
+	played        map[string]struct{}
+	skipped       bool
 	mu            sync.RWMutex
 
-	running           bool
+	running bool
+	wg      sync.WaitGroup
+	// persistWg tracks the loudness measurements and cache uploads, they
+	// outlive the player and aren't waited for when it stops.
+	persistWg         *sync.WaitGroup
 	youtubeRepository youtube.YouTubeService
+	cache             *AudioCache
+	loudness          *Loudness
+	// reconnectAttempts limits how often the player waits for the voice connection.
+	reconnectAttempts int
+	// awaitingVoice is set while the player waits for the voice connection.
+	awaitingVoice bool
 }
 
-func NewPlayer(vc *discordgo.VoiceConnection, youtubeRepository youtube.YouTubeService) *Player {
+func NewPlayer(vc *discordgo.VoiceConnection, youtubeRepository youtube.YouTubeService, cache *AudioCache, loudness *Loudness) *Player {
 	return &Player{
 		vc:            vc,
+		channelID:     vc.ChannelID,
 		queue:         make([]*youtube.Video, 0),
 		queuePosition: -1,
+		volume:        DefaultVolume,
+		played:        make(map[string]struct{}),
+		enqueued:      make(map[*youtube.Video]uint64),
 		logger: slog.With("player.go",
 			slog.Group("player", slog.String("guildID", vc.GuildID), slog.String("channelID", vc.ChannelID))),
 		youtubeRepository: youtubeRepository,
+		cache:             cache,
+		loudness:          loudness,
+		reconnectAttempts: DefaultVoiceReconnectAttempts,
+		persistWg:         &sync.WaitGroup{},
 	}
 
 }
 
-func (s *Player) EnqueueVideo(video *youtube.Video) error {
+// EnqueueVideo appends the video and returns its position relative to the
+// current video, which itself is at position 0.
+func (s *Player) EnqueueVideo(video *youtube.Video) (int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrPlayerClosed
+	}
 	s.queue = append(s.queue, video)
+	s.markEnqueued(video)
+	if s.fairQueue {
+		s.reorderUpcomingLocked()
+		return s.relativePosition(slices.Index(s.queue, video)), nil
+	}
 
-	return nil
+	return s.relativePosition(len(s.queue) - 1), nil
+}
+
+// InsertVideo puts the video at pos, 1 being right after the current video.
+// Positions past the end of the queue append the video.
+func (s *Player) InsertVideo(video *youtube.Video, pos int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrPlayerClosed
+	}
+	if s.fairQueue {
+		return 0, ErrFairQueueOrder
+	}
+	s.discardPrefetchedLocked()
+	if pos < 1 {
+		return 0, ErrInvalidPosition
+	}
+
+	idx := s.queuePosition + pos
+	if idx < 0 {
+		idx = 0
+	}
+	s.markEnqueued(video)
+	if idx >= len(s.queue) {
+		s.queue = append(s.queue, video)
+		return s.relativePosition(len(s.queue) - 1), nil
+	}
+
+	s.queue = append(s.queue[:idx], append([]*youtube.Video{video}, s.queue[idx:]...)...)
+
+	return s.relativePosition(idx), nil
+}
+
+func (s *Player) relativePosition(idx int) int {
+	if s.queuePosition < 0 {
+		return idx
+	}
+	return idx - s.queuePosition
 }
 
 func (s *Player) getNextVideo() *youtube.Video {
@@ -66,6 +191,8 @@ func (s *Player) getNextVideo() *youtube.Video {
 	defer s.mu.Unlock()
 
 	video := s.queue[s.queuePosition]
+	// votes only count for the video they were cast for
+	s.skipVotes = nil
 
 	return video
 }
@@ -74,10 +201,93 @@ func (s *Player) nextVideo() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	skipped := s.skipped
+	s.skipped = false
+	if s.loopMode == LoopTrack && !skipped && s.queuePosition >= 0 && s.queuePosition < len(s.queue) {
+		return true
+	}
+
 	s.queuePosition++
+	s.trimHistory()
+	if s.loopMode == LoopQueue && s.queuePosition >= len(s.queue) && len(s.queue) > 0 {
+		s.queuePosition = 0
+	}
 	return s.queuePosition < len(s.queue)
 }
 
+// trimHistory drops the oldest finished videos, unless the whole queue loops.
+func (s *Player) trimHistory() {
+	if s.loopMode == LoopQueue || s.queuePosition <= maxHistory {
+		return
+	}
+
+	drop := s.queuePosition - maxHistory
+	if drop > len(s.queue) {
+		drop = len(s.queue)
+	}
+	s.forgetEnqueued(s.queue[:drop]...)
+	s.queue = append(s.queue[:0:0], s.queue[drop:]...)
+	s.queuePosition -= drop
+}
+
+// History returns up to limit finished videos, the most recent first.
+func (s *Player) History(limit int) []*youtube.Video {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	end := s.queuePosition
+	if end > len(s.queue) {
+		end = len(s.queue)
+	}
+	res := make([]*youtube.Video, 0, limit)
+	for i := end - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, s.queue[i])
+	}
+	return res
+}
+
+// Previous stops the current video and plays the previous one again, after
+// the queue ran dry it plays the last video again.
+func (s *Player) Previous() (*youtube.Video, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	if s.queuePosition < 1 {
+		return nil, ErrNoPreviousVideo
+	}
+	if s.queuePosition >= len(s.queue) {
+		if s.closed {
+			return nil, ErrPlayerClosed
+		}
+		// the lingering player plays the video at queuePosition next
+		s.queuePosition = len(s.queue) - 1
+		return s.queue[s.queuePosition], nil
+	}
+	if s.skipFunc == nil {
+		return nil, ErrSkipNotPossible
+	}
+
+	s.skipFunc(ErrCauseSkip)
+	s.skipFunc = nil
+	s.skipped = true
+	s.queuePosition -= 2
+
+	return s.queue[s.queuePosition+1], nil
+}
+
+func (s *Player) SetLoopMode(mode LoopMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	s.loopMode = mode
+}
+
+func (s *Player) LoopMode() LoopMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loopMode
+}
+
 func (s *Player) waitForVideos(ctx context.Context) {
 	for {
 		if s.Count() > 0 {
@@ -93,26 +303,309 @@ func (s *Player) waitForVideos(ctx context.Context) {
 	}
 }
 
+// linger keeps the finished player around for the linger timeout. It reports
+// whether a video got enqueued in the meantime, queuePosition then points to it.
+func (s *Player) linger(ctx context.Context) bool {
+	s.mu.Lock()
+	timeout := s.lingerTimeout
+	if s.queuePosition > len(s.queue) {
+		s.queuePosition = len(s.queue)
+	}
+	s.mu.Unlock()
+	if timeout <= 0 {
+		return false
+	}
+
+	s.logger.Info("queue is empty, lingering", "timeout", timeout.String())
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		if s.queuePosition > len(s.queue) {
+			s.queuePosition = len(s.queue)
+		}
+		enqueued := s.queuePosition < len(s.queue)
+		s.mu.Unlock()
+		if enqueued {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline:
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.queuePosition < len(s.queue) {
+				return true
+			}
+			// nothing can be enqueued from here on
+			s.closed = true
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (s *Player) SetLingerTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lingerTimeout = timeout
+}
+
+func (s *Player) LingerTimeout() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lingerTimeout
+}
+
 func (s *Player) Skip(cnt int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.skipFunc == nil {
+	return s.skipLocked(cnt)
+}
+
+func (s *Player) skipLocked(cnt int) error {
+	s.discardPrefetchedLocked()
+	if !s.skippableLocked() {
 		return ErrSkipNotPossible
 	}
 
 	s.skipFunc(ErrCauseSkip)
 	s.skipFunc = nil
+	s.skipped = true
 
 	s.queuePosition += (cnt - 1)
 
 	return nil
 }
 
+// skippableLocked reports whether a track is playing that can be skipped.
+func (s *Player) skippableLocked() bool {
+	return s.skipFunc != nil && s.currentLocked() != nil
+}
+
+func (s *Player) Pause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stream == nil {
+		return ErrPlaybackIsNotRunning
+	}
+	if s.paused {
+		return ErrAlreadyPaused
+	}
+
+	s.stream.SetPaused(true)
+	s.paused = true
+
+	return s.vc.Speaking(false)
+}
+
+func (s *Player) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stream == nil {
+		return ErrPlaybackIsNotRunning
+	}
+	if !s.paused {
+		return ErrNotPaused
+	}
+
+	if err := s.vc.Speaking(true); err != nil {
+		return err
+	}
+	s.stream.SetPaused(false)
+	s.paused = false
+
+	return nil
+}
+
+func (s *Player) IsPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
+func (s *Player) setStream(stream *dca.StreamingSession, start time.Duration, tempo float64) {
+	s.mu.Lock()
+	s.stream = stream
+	s.startOffset = start
+	s.streamTempo = tempo
+	s.paused = false
+	s.mu.Unlock()
+}
+
+// Current returns the video that is currently playing, nil if there is none.
+func (s *Player) Current() *youtube.Video {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.currentLocked()
+}
+
+func (s *Player) currentLocked() *youtube.Video {
+	if s.queuePosition < 0 || s.queuePosition >= len(s.queue) {
+		return nil
+	}
+	return s.queue[s.queuePosition]
+}
+
+// Queue returns a copy of the current video followed by the upcoming ones.
 func (s *Player) Queue() []*youtube.Video {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.queue[s.queuePosition:]
+	start := s.queuePosition
+	if start < 0 {
+		start = 0
+	}
+	if start > len(s.queue) {
+		start = len(s.queue)
+	}
+
+	res := make([]*youtube.Video, len(s.queue)-start)
+	copy(res, s.queue[start:])
+	return res
+}
+
+// RequesterUsage returns how many of the current and upcoming videos the user
+// requested and their total length.
+func (s *Player) RequesterUsage(userID string) (int, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cnt := 0
+	var total time.Duration
+	for i := max(s.queuePosition, 0); i < len(s.queue); i++ {
+		if s.queue[i].RequesterID != userID {
+			continue
+		}
+		cnt++
+		total += s.queue[i].Duration()
+	}
+
+	return cnt, total
+}
+
+// upcomingLocked returns the videos after the current one. Before the first
+// video started that's the whole queue, once the queue ran dry it is empty.
+func (s *Player) upcomingLocked() []*youtube.Video {
+	return s.queue[min(max(s.queuePosition+1, 0), len(s.queue)):]
+}
+
+// Positions passed to the queue editing methods are relative to the current
+// video: 1 is the next video, 2 the one after it and so on.
+
+func (s *Player) upcomingIndex(pos int) (int, error) {
+	idx := s.queuePosition + pos
+	if pos < 1 || idx >= len(s.queue) {
+		return 0, ErrInvalidPosition
+	}
+	return idx, nil
+}
+
+func (s *Player) Remove(pos int) (*youtube.Video, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	idx, err := s.upcomingIndex(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.removeLocked(idx), nil
+}
+
+// RemoveIfRequester removes the video at pos like Remove, but only if the user
+// requested it.
+func (s *Player) RemoveIfRequester(pos int, userID string) (*youtube.Video, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.upcomingIndex(pos)
+	if err != nil {
+		return nil, err
+	}
+	if s.queue[idx].RequesterID != userID {
+		return nil, ErrNotRequester
+	}
+
+	s.discardPrefetchedLocked()
+	return s.removeLocked(idx), nil
+}
+
+func (s *Player) removeLocked(idx int) *youtube.Video {
+	video := s.queue[idx]
+	s.queue = append(s.queue[:idx], s.queue[idx+1:]...)
+	s.forgetEnqueued(video)
+	return video
+}
+
+func (s *Player) Move(from, to int) (*youtube.Video, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fairQueue {
+		return nil, ErrFairQueueOrder
+	}
+	s.discardPrefetchedLocked()
+	fromIdx, err := s.upcomingIndex(from)
+	if err != nil {
+		return nil, err
+	}
+	toIdx, err := s.upcomingIndex(to)
+	if err != nil {
+		return nil, err
+	}
+
+	video := s.queue[fromIdx]
+	s.queue = append(s.queue[:fromIdx], s.queue[fromIdx+1:]...)
+	s.queue = append(s.queue[:toIdx], append([]*youtube.Video{video}, s.queue[toIdx:]...)...)
+
+	return video, nil
+}
+
+// Shuffle shuffles the upcoming videos, the current one keeps playing.
+func (s *Player) Shuffle() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fairQueue {
+		return ErrFairQueueOrder
+	}
+	s.discardPrefetchedLocked()
+	upcoming := s.upcomingLocked()
+	rand.Shuffle(len(upcoming), func(i, j int) {
+		upcoming[i], upcoming[j] = upcoming[j], upcoming[i]
+	})
+	return nil
+}
+
+// Clear removes all upcoming videos, the current one keeps playing.
+func (s *Player) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	upcoming := s.upcomingLocked()
+	s.forgetEnqueued(upcoming...)
+	s.queue = s.queue[:len(s.queue)-len(upcoming)]
+	return len(upcoming)
+}
+
+// SkipTo stops the current video and continues playback at pos.
+func (s *Player) SkipTo(pos int) (*youtube.Video, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	idx, err := s.upcomingIndex(pos)
+	if err != nil {
+		return nil, err
+	}
+	if s.skipFunc == nil {
+		return nil, ErrSkipNotPossible
+	}
+
+	s.skipFunc(ErrCauseSkip)
+	s.skipFunc = nil
+	s.skipped = true
+	s.queuePosition = idx - 1
+
+	return s.queue[idx], nil
 }
 
 func (s *Player) Run(ctx context.Context) error {
@@ -122,11 +615,24 @@ func (s *Player) Run(ctx context.Context) error {
 
 	s.setRunning(true)
 	defer s.setRunning(false)
+	defer s.close()
+	// a prefetch still running may set prefetched
+	defer s.discardPrefetched()
+	defer s.wg.Wait()
 	s.waitForVideos(ctx)
 
-	for s.nextVideo() {
+	for s.nextVideo() || s.autoplayNow(ctx) || s.linger(ctx) {
 		video := s.getNextVideo()
+		if s.markPlayed(video) {
+			utils.BackgroundTask(&s.wg, func() error {
+				_, err := s.enqueueRelated(ctx, video)
+				return err
+			})
+		}
 
+		if err := s.awaitVoice(ctx); err != nil {
+			return err
+		}
 		s.mu.Lock()
 		err := s.vc.Speaking(true)
 		s.mu.Unlock()
@@ -134,14 +640,44 @@ func (s *Player) Run(ctx context.Context) error {
 			return err
 		}
 
-		skipCtx, skipFunc := context.WithCancelCause(ctx)
+		s.logger.Info("player", "guild", s.vc.GuildID, "video", video.Title)
+		start := time.Duration(0)
+		reconnects := 0
+		for {
+			t := s.takePrefetched(video, start)
+			if t == nil {
+				t, err = s.openTrack(ctx, video, start)
+				if err != nil {
+					return err
+				}
+			}
 
-		s.mu.Lock()
-		s.skipFunc = skipFunc
-		s.mu.Unlock()
+			skipCtx, release := s.newSkipContext(ctx)
+			err = s.playTrack(ctx, skipCtx, t)
+			release()
+			if s.shouldReconnectLive(ctx, video, err, &reconnects) {
+				start = 0
+				continue
+			}
+			if errors.Is(err, ErrCauseVoiceLost) {
+				if err := s.awaitVoice(ctx); err != nil {
+					return err
+				}
+			} else if !errors.Is(err, ErrCauseSeek) {
+				break
+			}
 
-		s.logger.Info("player", "guild", s.vc.GuildID, "video", video.Title)
-		err = s.playAudioFromURL(skipCtx, video.URL, s.vc)
+			start = s.takeSeekPosition()
+			if video.Live {
+				start = 0
+			}
+			s.mu.Lock()
+			err = s.vc.Speaking(true)
+			s.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		}
 		if err != nil && !errors.Is(err, ErrCauseSkip) {
 			return err
 		}
@@ -149,7 +685,8 @@ func (s *Player) Run(ctx context.Context) error {
 		s.mu.Lock()
 		err = s.vc.Speaking(false)
 		s.mu.Unlock()
-		if err != nil {
+		// a lost connection is awaited before the next track
+		if err != nil && s.voiceReady() {
 			return err
 		}
 		s.logger.Info("player", "guild", s.vc.GuildID, "video", video.Title)
@@ -159,6 +696,324 @@ func (s *Player) Run(ctx context.Context) error {
 	return nil
 }
 
+// shouldReconnectLive reports whether a live stream stopped without being
+// skipped or stopped, while it is still live. It waits before reconnecting.
+func (s *Player) shouldReconnectLive(ctx context.Context, video *youtube.Video, err error, attempts *int) bool {
+	if !video.Live || ctx.Err() != nil || errors.Is(err, ErrCauseSkip) || errors.Is(err, ErrCauseSeek) || errors.Is(err, ErrCauseVoiceLost) {
+		return false
+	}
+	if *attempts >= liveReconnectAttempts {
+		s.logger.Info("giving up on live stream", "video", video.ID)
+		return false
+	}
+
+	data, dataErr := s.youtubeRepository.GetYoutubeData(ctx, video.URL)
+	if dataErr != nil || !data.IsLiveStream() {
+		return false
+	}
+
+	*attempts++
+	s.logger.Info("reconnecting live stream", "video", video.ID, "attempt", *attempts)
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(liveReconnectDelay):
+	}
+	return true
+}
+
+func (s *Player) SetAutoplay(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoplay = enabled
+}
+
+func (s *Player) Autoplay() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.autoplay
+}
+
+// markPlayed remembers the video for autoplay and reports whether it is the
+// last one in the queue while autoplay is enabled.
+func (s *Player) markPlayed(video *youtube.Video) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.played[video.ID] = struct{}{}
+	return s.autoplay && s.loopMode == LoopOff && s.queuePosition == len(s.queue)-1
+}
+
+// autoplayNow is the fallback for when autoplay got enabled during the last
+// track, it fills the queue before the session would end.
+func (s *Player) autoplayNow(ctx context.Context) bool {
+	s.mu.RLock()
+	// a skip can move queuePosition past the end of the queue
+	lastIdx := min(s.queuePosition, len(s.queue)) - 1
+	enabled := s.autoplay && lastIdx >= 0
+	var last *youtube.Video
+	if enabled {
+		last = s.queue[lastIdx]
+	}
+	s.mu.RUnlock()
+	if !enabled {
+		return false
+	}
+
+	if _, err := s.enqueueRelated(ctx, last); err != nil {
+		s.logger.Error("autoplay failed", slog.String("error", err.Error()))
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.queuePosition < len(s.queue)
+}
+
+// enqueueRelated queues up to autoplayBatch related videos that were neither
+// played this session nor are already queued.
+func (s *Player) enqueueRelated(ctx context.Context, video *youtube.Video) (int, error) {
+	songs, err := s.youtubeRepository.GetRelatedVideos(ctx, video.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	known := make(map[string]struct{}, len(s.played)+len(s.queue))
+	for id := range s.played {
+		known[id] = struct{}{}
+	}
+	for _, v := range s.upcomingLocked() {
+		known[v.ID] = struct{}{}
+	}
+
+	cnt := 0
+	for _, song := range songs {
+		if cnt == autoplayBatch {
+			break
+		}
+		if _, ok := known[song.ID]; ok || song.IsLive {
+			continue
+		}
+		known[song.ID] = struct{}{}
+
+		related := youtube.NewVideo("https://www.youtube.com/watch?v="+song.ID, song, youtube.SourceAutoplay)
+		related.RequesterName = "Autoplay"
+		s.queue = append(s.queue, related)
+		s.markEnqueued(related)
+		cnt++
+	}
+	s.logger.Info("autoplay queued related videos", "video", video.ID, "count", cnt)
+
+	return cnt, nil
+}
+
+// peekNextVideoLocked returns the video nextVideo would move to.
+func (s *Player) peekNextVideoLocked() *youtube.Video {
+	if s.skipped {
+		return nil
+	}
+	if s.loopMode == LoopTrack && s.queuePosition >= 0 && s.queuePosition < len(s.queue) {
+		return s.queue[s.queuePosition]
+	}
+
+	idx := s.queuePosition + 1
+	if s.loopMode == LoopQueue && idx >= len(s.queue) {
+		idx = 0
+	}
+	if idx < 0 || idx >= len(s.queue) {
+		return nil
+	}
+	return s.queue[idx]
+}
+
+// prefetchNext opens the next video's track once the current video is
+// within prefetchLead of its end.
+func (s *Player) prefetchNext(ctx context.Context, current *youtube.Video) {
+	total := current.Duration()
+	if total == 0 || s.IsPaused() || s.Position() < total-prefetchLead {
+		return
+	}
+
+	s.mu.Lock()
+	next := s.peekNextVideoLocked()
+	if s.prefetched != nil || s.prefetching || next == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.prefetching = true
+	s.mu.Unlock()
+
+	// opening may download the whole cached audio, the playback mustn't wait for it
+	utils.BackgroundTask(&s.wg, func() error {
+		t, err := s.openTrack(ctx, next, 0)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.prefetching = false
+		if err != nil {
+			return fmt.Errorf("failed to prefetch next video: %w", err)
+		}
+		if s.prefetched != nil || s.peekNextVideoLocked() != next {
+			go t.close()
+			return nil
+		}
+		s.prefetched = t
+		return nil
+	})
+}
+
+// takePrefetched returns the prefetched track if it belongs to video and
+// starts at the beginning, any other prefetched track is discarded.
+func (s *Player) takePrefetched(video *youtube.Video, start time.Duration) *track {
+	s.mu.Lock()
+	t := s.prefetched
+	s.prefetched = nil
+	s.mu.Unlock()
+
+	if t != nil && (t.video != video || start != 0) {
+		t.close()
+		return nil
+	}
+	return t
+}
+
+func (s *Player) discardPrefetched() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+}
+
+func (s *Player) discardPrefetchedLocked() {
+	if s.prefetched == nil {
+		return
+	}
+	go s.prefetched.close()
+	s.prefetched = nil
+}
+
+// newSkipContext returns the context skipping the track cancels and a func
+// that releases it once the track stopped, so nothing is skipped while idle.
+func (s *Player) newSkipContext(ctx context.Context) (context.Context, func()) {
+	skipCtx, skipFunc := context.WithCancelCause(ctx)
+
+	s.mu.Lock()
+	s.skipFunc = skipFunc
+	s.mu.Unlock()
+
+	return skipCtx, func() {
+		s.mu.Lock()
+		s.skipFunc = nil
+		s.mu.Unlock()
+		skipFunc(nil)
+	}
+}
+
+func (s *Player) takeSeekPosition() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pos := s.seekTo
+	s.seekTo = 0
+	return pos
+}
+
+// Seek restarts the current track at pos without moving the queue position.
+func (s *Player) Seek(pos time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isLiveLocked() {
+		return ErrSeekLiveStream
+	}
+	return s.seekLocked(pos)
+}
+
+// SeekBy moves the current track's position by delta.
+func (s *Player) SeekBy(delta time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isLiveLocked() {
+		return ErrSeekLiveStream
+	}
+	return s.seekLocked(s.positionLocked() + delta)
+}
+
+func (s *Player) isLiveLocked() bool {
+	return s.queuePosition >= 0 && s.queuePosition < len(s.queue) && s.queue[s.queuePosition].Live
+}
+
+// seekLocked restarts the current track at pos, live streams restart at
+// the live edge.
+func (s *Player) seekLocked(pos time.Duration) error {
+	if s.skipFunc == nil || s.stream == nil {
+		return ErrPlaybackIsNotRunning
+	}
+	if pos < 0 || s.isLiveLocked() {
+		pos = 0
+	}
+	if current := s.currentLocked(); current != nil && current.Duration() > 0 && pos >= current.Duration() {
+		return ErrSeekOutOfRange
+	}
+
+	s.seekTo = pos
+	s.skipFunc(ErrCauseSeek)
+	s.skipFunc = nil
+
+	return nil
+}
+
+// Position returns the playback position within the current track.
+func (s *Player) Position() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.positionLocked()
+}
+
+func (s *Player) positionLocked() time.Duration {
+	if s.stream == nil {
+		return 0
+	}
+	// the stream counts played time, speed filters play the source faster or slower
+	return s.startOffset + time.Duration(float64(s.stream.PlaybackPosition())*s.streamTempo)
+}
+
+// SetVolume sets the volume in percent. It applies from the next encoding session.
+func (s *Player) SetVolume(volume int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	s.volume = volume
+}
+
+func (s *Player) Volume() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.volume
+}
+
+// SetFilter stores the audio filter and restarts the current track at the
+// same position, so the filter applies right away.
+func (s *Player) SetFilter(filter Filter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardPrefetchedLocked()
+	s.filter = filter
+	if s.skipFunc != nil && s.stream != nil {
+		_ = s.seekLocked(s.positionLocked())
+	}
+}
+
+func (s *Player) Filter() Filter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filter
+}
+
+func (s *Player) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
 func (s *Player) setRunning(val bool) {
 	s.mu.Lock()
 	s.running = val
@@ -186,106 +1041,145 @@ func (s *Player) Count() int {
 func (s *Player) ChannelID() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.vc.ChannelID
+	return s.channelID
 }
 
-func (s *Player) playAudioFromURL(ctx context.Context, url string, vc *discordgo.VoiceConnection) error {
-	ytdlp := s.youtubeRepository.PlayVideo(ctx, url)
-	stdout, err := ytdlp.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	stderr, err := ytdlp.StderrPipe()
-	if err != nil {
-		return err
-	}
+// SetChannelID records that the bot was moved to another voice channel.
+func (s *Player) SetChannelID(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channelID = channelID
+}
 
-	options := dca.StdEncodeOptions
-	options.RawOutput = true
-	options.Bitrate = 128
-	options.Channels = 2
-	options.Application = dca.AudioApplicationLowDelay
-	options.VolumeFloat = 1.0
-	options.VBR = true
-	options.Threads = 0
-	options.BufferedFrames = 100
-	options.PacketLoss = 0
-	options.FrameDuration = 20
+// voiceLost remembers the position of the track to resume it from there once
+// the voice connection is back.
+func (s *Player) voiceLost(t *track) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seekTo = s.positionLocked()
+	t.logger.Info("voice connection lost", "position", s.seekTo.String())
+	return ErrCauseVoiceLost
+}
 
-	session, err := dca.EncodeMem(stdout, options)
-	if err != nil {
-		return err
+func (s *Player) voiceReady() bool {
+	s.vc.RLock()
+	defer s.vc.RUnlock()
+	return s.vc.Ready
+}
+
+// awaitVoice waits for discordgo to restore the voice connection. It gives up
+// with ErrVoiceReconnectFailed after the configured number of attempts.
+func (s *Player) awaitVoice(ctx context.Context) error {
+	if s.voiceReady() {
+		return nil
 	}
-	defer session.Cleanup()
 
-	done := make(chan error)
-	dca.NewStream(session, vc, done)
+	s.mu.Lock()
+	attempts := s.reconnectAttempts
+	s.awaitingVoice = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.awaitingVoice = false
+		s.mu.Unlock()
+	}()
 
-	err = ytdlp.Start()
-	if err != nil {
-		return err
+	tick := time.NewTicker(500 * time.Millisecond)
+	defer tick.Stop()
+	for attempt := 1; attempt <= attempts; attempt++ {
+		s.logger.Info("waiting for voice connection", "attempt", attempt, "of", attempts)
+		deadline := time.After(voiceReconnectWait)
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-deadline:
+				break wait
+			case <-tick.C:
+				if s.voiceReady() {
+					s.logger.Info("voice connection restored", "attempt", attempt)
+					return nil
+				}
+			}
+		}
 	}
 
-	defer func(ytCmd *exec.Cmd, ytStdout io.ReadCloser, lg *slog.Logger) {
-		err := ytCmd.Wait()
-		if err != nil {
-			s.logger.Error("player", slog.String("error", err.Error()))
-		}
-		_, _ = io.Copy(io.Discard, ytStdout)
-		_ = ytStdout.Close()
-	}(ytdlp, stdout, s.logger)
-
-	go func() {
-		sc := bufio.NewScanner(stderr)
-		for sc.Scan() {
-			s.logger.Info("player", slog.String("ytdlp stderr", sc.Text()))
-		}
+	return ErrVoiceReconnectFailed
+}
 
-		if err := sc.Err(); err != nil {
-			s.logger.Error("player", slog.String("ytdlp stderr reader error", err.Error()))
-		}
-	}()
+// Reconnecting reports whether the voice connection is down or the player
+// waits for it to come back. discordgo leaves the channel between failed
+// attempts to rejoin it, which mustn't be mistaken for a disconnect.
+func (s *Player) Reconnecting() bool {
+	s.mu.RLock()
+	awaiting := s.awaitingVoice
+	s.mu.RUnlock()
+	return awaiting || !s.voiceReady()
+}
 
-	select {
-	case <-ctx.Done():
-		if err := session.Stop(); err != nil {
-			s.logger.Error("failed to stop encoding session", slog.String("error", err.Error()))
-			return err
-		}
+// SetPersistWaitGroup sets the wait group that tracks the loudness
+// measurements and cache uploads, so the service can wait for them.
+func (s *Player) SetPersistWaitGroup(wg *sync.WaitGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.persistWg = wg
+}
 
-		if err := ytdlp.Process.Kill(); err != nil {
-			s.logger.Error("failed to kill yt-dlp process", slog.String("error", err.Error()))
-			return err
-		}
+func (s *Player) SetReconnectAttempts(attempts int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reconnectAttempts = attempts
+}
 
-		return context.Cause(ctx)
-	case err := <-done:
-		if err != nil {
+// playTrack streams the track until it ends or skipCtx is cancelled. Close to
+// the end of the track the next video gets prefetched with runCtx.
+func (s *Player) playTrack(runCtx, skipCtx context.Context, t *track) error {
+	defer t.close()
+
+	done := make(chan error)
+	s.setStream(dca.NewStream(t.session, s.vc, done), t.start, t.tempo)
+	defer s.setStream(nil, 0, 1)
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-skipCtx.Done():
+			return context.Cause(skipCtx)
+		case <-tick.C:
+			if !s.IsPaused() && !s.voiceReady() {
+				return s.voiceLost(t)
+			}
+			s.prefetchNext(runCtx, t.video)
+		case err := <-done:
 			if err == io.EOF {
-				s.logger.Info("player", slog.String("message", "playback finished"))
+				t.logger.Info("player", slog.String("message", "playback finished"))
+				t.finished = true
 				return nil
 			}
+			if errors.Is(err, dca.ErrVoiceConnClosed) {
+				return s.voiceLost(t)
+			}
 
-			errBuf, _ := io.ReadAll(stderr)
-			s.logger.Error("player", slog.String("error", "error occured while playing audio"), slog.String("ffmpeg messages", session.FFMPEGMessages()), slog.String("ytdlp", string(errBuf)))
-			_, _ = io.Copy(io.Discard, stderr)
-			_ = stderr.Close()
-
+			t.logger.Error("player", slog.String("error", "error occured while playing audio"), slog.String("ffmpeg messages", t.session.FFMPEGMessages()))
 			return err
 		}
 	}
-
-	return err
 }
 
 func (s *Player) EnqueuePlaylist(videos []*youtube.Video) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if !s.running {
-		return ErrPlaybackIsNotRunning
+	if s.closed {
+		return ErrPlayerClosed
 	}
-
 	s.queue = append(s.queue, videos...)
+	s.markEnqueued(videos...)
+	if s.fairQueue {
+		s.reorderUpcomingLocked()
+	}
 
 	return nil
 }
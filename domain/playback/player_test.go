@@ -0,0 +1,208 @@
+package playback
+
+import (
+	"errors"
+	"testing"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// queueStates are the queue positions a player goes through: before the first
+// video, while playing and after the queue ran dry or a skip overshot it.
+var queueStates = []struct {
+	name          string
+	queuePosition int
+	// upcoming is the number of videos after the current one
+	upcoming int
+}{
+	{name: "not started", queuePosition: -1, upcoming: 3},
+	{name: "playing first", queuePosition: 0, upcoming: 2},
+	{name: "playing last", queuePosition: 2, upcoming: 0},
+	{name: "ran dry", queuePosition: 3, upcoming: 0},
+	{name: "skipped past end", queuePosition: 5, upcoming: 0},
+}
+
+func newTestPlayer(queuePosition int) *Player {
+	p := NewPlayer(&discordgo.VoiceConnection{}, nil, nil, nil)
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := p.EnqueueVideo(&youtube.Video{ID: id}); err != nil {
+			panic(err)
+		}
+	}
+	p.queuePosition = queuePosition
+	return p
+}
+
+func TestShuffle(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+			current := p.Current()
+
+			if err := p.Shuffle(); err != nil {
+				t.Fatalf("Shuffle() error = %v", err)
+			}
+
+			if got := len(p.queue); got != 3 {
+				t.Errorf("queue length = %d, want 3", got)
+			}
+			if got := p.Current(); got != current {
+				t.Errorf("current video changed from %v to %v", current, got)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+			current := p.Current()
+
+			if got := p.Clear(); got != tt.upcoming {
+				t.Errorf("Clear() = %d, want %d", got, tt.upcoming)
+			}
+			if got := len(p.queue); got != 3-tt.upcoming {
+				t.Errorf("queue length = %d, want %d", got, 3-tt.upcoming)
+			}
+			if got := p.Current(); got != current {
+				t.Errorf("current video changed from %v to %v", current, got)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+
+			_, err := p.Remove(1)
+			if tt.upcoming == 0 {
+				if !errors.Is(err, ErrInvalidPosition) {
+					t.Errorf("Remove(1) error = %v, want %v", err, ErrInvalidPosition)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Remove(1) error = %v", err)
+			}
+			if got := len(p.queue); got != 2 {
+				t.Errorf("queue length = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestRemoveIfRequester(t *testing.T) {
+	p := newTestPlayer(0)
+	p.queue[1].RequesterID = "u"
+
+	if _, err := p.RemoveIfRequester(2, "u"); !errors.Is(err, ErrNotRequester) {
+		t.Errorf("RemoveIfRequester(2) error = %v, want %v", err, ErrNotRequester)
+	}
+	video, err := p.RemoveIfRequester(1, "u")
+	if err != nil {
+		t.Fatalf("RemoveIfRequester(1) error = %v", err)
+	}
+	if video.ID != "b" || len(p.queue) != 2 {
+		t.Errorf("removed %s, queue length = %d, want b and 2", video.ID, len(p.queue))
+	}
+}
+
+func TestMove(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+
+			_, err := p.Move(1, 2)
+			if tt.upcoming < 2 {
+				if !errors.Is(err, ErrInvalidPosition) {
+					t.Errorf("Move(1, 2) error = %v, want %v", err, ErrInvalidPosition)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Move(1, 2) error = %v", err)
+			}
+			if got := len(p.queue); got != 3 {
+				t.Errorf("queue length = %d, want 3", got)
+			}
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+
+			want := tt.upcoming
+			if p.Current() != nil {
+				want++
+			}
+			if got := len(p.Queue()); got != want {
+				t.Errorf("len(Queue()) = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+			p.skipFunc = func(error) {}
+			idle := p.Current() == nil
+
+			err := p.Skip(3)
+			if idle {
+				if !errors.Is(err, ErrSkipNotPossible) {
+					t.Errorf("Skip(3) error = %v, want %v", err, ErrSkipNotPossible)
+				}
+				if p.queuePosition != tt.queuePosition {
+					t.Errorf("queuePosition = %d, want %d", p.queuePosition, tt.queuePosition)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Skip(3) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	for _, tt := range queueStates {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlayer(tt.queuePosition)
+			p.skipFunc = func(error) {}
+
+			video, err := p.Previous()
+			switch {
+			case tt.queuePosition < 1:
+				if !errors.Is(err, ErrNoPreviousVideo) {
+					t.Errorf("Previous() error = %v, want %v", err, ErrNoPreviousVideo)
+				}
+			case tt.queuePosition >= 3:
+				// the lingering player continues at queuePosition
+				if err != nil {
+					t.Fatalf("Previous() error = %v", err)
+				}
+				if video.ID != "c" || p.Current() != video {
+					t.Errorf("Previous() = %s, current = %v, want c", video.ID, p.Current())
+				}
+			default:
+				// playing continues after the position got incremented
+				if err != nil {
+					t.Fatalf("Previous() error = %v", err)
+				}
+				if got := p.queue[p.queuePosition+1]; got != video {
+					t.Errorf("next video = %s, want %s", got.ID, video.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package playback
+
+import (
+	"context"
+	"encoding/json"
+	"jnelle/discord-music-bot/common"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultVolume        = 100
+	DefaultLingerTimeout = 2 * time.Minute
+
+	DefaultVoteSkipListeners = 2
+	DefaultVoteSkipPercent   = 50
+)
+
+// settingsTimeout limits how long saving the settings of a guild may take.
+const settingsTimeout = 10 * time.Second
+
+// Settings are per guild and outlive the guild's player.
+type Settings struct {
+	// Volume in percent, 100 is the original loudness.
+	Volume int `json:"volume"`
+	// Autoplay queues related videos when the queue runs dry
+	Autoplay bool `json:"autoplay"`
+	// LingerTimeout is how long the bot stays in the channel after the queue ended
+	LingerTimeout time.Duration `json:"linger_timeout"`
+	// MaxTracksPerUser limits the queued tracks of each user, 0 disables the limit
+	MaxTracksPerUser int `json:"max_tracks_per_user"`
+	// MaxMinutesPerUser limits the queued minutes of each user, 0 disables the limit
+	MaxMinutesPerUser int `json:"max_minutes_per_user"`
+	// PlayCooldown is the time each user has to wait between /play calls
+	PlayCooldown time.Duration `json:"play_cooldown"`
+	// FairQueue interleaves the upcoming videos by requester
+	FairQueue bool `json:"fair_queue"`
+	// VoteSkipListeners is how many listeners skip without a vote, more have to vote
+	VoteSkipListeners int `json:"vote_skip_listeners"`
+	// VoteSkipPercent is the share of listeners that has to vote, 0 disables voting
+	VoteSkipPercent int `json:"vote_skip_percent"`
+	// DJRoleID is the role allowed to use all commands, everyone is allowed without it
+	DJRoleID string `json:"dj_role_id"`
+}
+
+// SettingsStorage keeps the settings of all guilds in memory and saves every
+// change to the settings service.
+type SettingsStorage struct {
+	mu       sync.RWMutex
+	settings map[string]Settings
+	db       common.SettingsService
+	// writers serializes the updates of each guild, so reading the settings
+	// doesn't wait for a save.
+	writers Map[string, *sync.Mutex]
+}
+
+func NewSettingsStorage(db common.SettingsService) *SettingsStorage {
+	return &SettingsStorage{
+		settings: map[string]Settings{},
+		db:       db,
+	}
+}
+
+// Load reads the saved settings of all guilds. It has to succeed before any
+// command runs, otherwise restrictions like the DJ role wouldn't apply.
+func (m *SettingsStorage) Load(ctx context.Context) error {
+	saved, err := m.db.ListSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, gs := range saved {
+		// settings added later keep their defaults
+		s := DefaultSettings()
+		if err := json.Unmarshal(gs.Settings, &s); err != nil {
+			return err
+		}
+		m.settings[gs.ID] = s
+	}
+
+	return nil
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Volume:            DefaultVolume,
+		LingerTimeout:     DefaultLingerTimeout,
+		VoteSkipListeners: DefaultVoteSkipListeners,
+		VoteSkipPercent:   DefaultVoteSkipPercent,
+	}
+}
+
+func (m *SettingsStorage) Get(guildID string) Settings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.getLocked(guildID)
+}
+
+func (m *SettingsStorage) getLocked(guildID string) Settings {
+	if s, ok := m.settings[guildID]; ok {
+		return s
+	}
+
+	return DefaultSettings()
+}
+
+// Update changes the settings of a guild. They are only changed once they
+// were saved.
+func (m *SettingsStorage) Update(guildID string, fn func(s *Settings)) (Settings, error) {
+	writer, _ := m.writers.LoadOrStore(guildID, &sync.Mutex{})
+	writer.Lock()
+	defer writer.Unlock()
+
+	old := m.Get(guildID)
+	s := old
+	fn(&s)
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return old, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), settingsTimeout)
+	defer cancel()
+	if err := m.db.UpdateSettings(ctx, &common.GuildSettings{ID: guildID, Settings: b}); err != nil {
+		return old, err
+	}
+
+	m.mu.Lock()
+	m.settings[guildID] = s
+	m.mu.Unlock()
+
+	return s, nil
+}
@@ -0,0 +1,264 @@
+package playback
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	youtube "jnelle/discord-music-bot/adapter/youtube_dlp"
+	"jnelle/discord-music-bot/utils"
+
+	"github.com/ClintonCollins/dca"
+)
+
+// track is a running yt-dlp and ffmpeg pipeline for a video. The encoder
+// starts buffering frames as soon as the track is opened, so a track can be
+// opened ahead of time and streamed later.
+//
+// Tracks of cached videos are read from the audio cache instead of yt-dlp.
+// Otherwise the downloaded audio is captured and, once the track played until
+// the end, measured for loudness and uploaded to the cache.
+type track struct {
+	video *youtube.Video
+	start time.Duration
+	// tempo is the speed factor of the track's filter
+	tempo   float64
+	ytdlp   *exec.Cmd
+	stdout  io.ReadCloser
+	session *dca.EncodeSession
+	cancel  context.CancelFunc
+	logger  *slog.Logger
+
+	capture  *captureBuffer
+	cache    *AudioCache
+	loudness *Loudness
+	wg       *sync.WaitGroup
+	finished bool
+}
+
+func (s *Player) openTrack(ctx context.Context, video *youtube.Video, start time.Duration) (*track, error) {
+	normalization, measured := "", true
+	if s.loudness != nil {
+		if video.Live {
+			normalization = dynamicNormalizer
+		} else {
+			normalization, measured = s.loudness.Filter(ctx, video.ID)
+		}
+	}
+
+	if s.cache != nil && !video.Live {
+		data, err := s.cache.Get(ctx, video.ID)
+		if err == nil {
+			if !measured {
+				s.measureLoudness(video, data)
+			}
+			return s.openCachedTrack(video, start, normalization, data)
+		}
+		if !errors.Is(err, ErrNotCached) {
+			s.logger.Error("failed to read audio cache", slog.String("error", err.Error()))
+		}
+	}
+
+	trackCtx, cancel := context.WithCancel(ctx)
+
+	ytdlp := s.youtubeRepository.PlayVideo(trackCtx, video.URL, start)
+	stdout, err := ytdlp.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stderr, err := ytdlp.StderrPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	var input io.Reader = stdout
+	var capture *captureBuffer
+	measure := s.loudness != nil && !measured
+	if start == 0 && !video.Live && (s.cache != nil || measure) {
+		capture = &captureBuffer{}
+		input = io.TeeReader(stdout, capture)
+	}
+
+	// yt-dlp already starts the stream at start
+	filter := s.Filter()
+	options := s.encodeOptions(0, normalization, filter)
+	session, err := dca.EncodeMem(input, &options)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := ytdlp.Start(); err != nil {
+		session.Cleanup()
+		cancel()
+		return nil, err
+	}
+
+	t := &track{
+		video:   video,
+		start:   start,
+		tempo:   filter.Tempo(),
+		ytdlp:   ytdlp,
+		stdout:  stdout,
+		session: session,
+		cancel:  cancel,
+		logger:  s.logger.With("video", video.ID),
+		capture: capture,
+		cache:   s.cache,
+		wg:      s.persistWg,
+	}
+	if measure {
+		t.loudness = s.loudness
+	}
+
+	go func() {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			t.logger.Info("player", slog.String("ytdlp stderr", sc.Text()))
+		}
+
+		if err := sc.Err(); err != nil {
+			t.logger.Error("player", slog.String("ytdlp stderr reader error", err.Error()))
+		}
+	}()
+
+	return t, nil
+}
+
+func (s *Player) openCachedTrack(video *youtube.Video, start time.Duration, normalization string, data []byte) (*track, error) {
+	// the audio is in memory, skipping to start only costs decoding
+	filter := s.Filter()
+	options := s.encodeOptions(start, normalization, filter)
+	session, err := dca.EncodeMem(bytes.NewReader(data), &options)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("playing cached audio", "video", video.ID)
+
+	return &track{
+		video:   video,
+		start:   start,
+		tempo:   filter.Tempo(),
+		session: session,
+		cancel:  func() {},
+		logger:  s.logger.With("video", video.ID),
+	}, nil
+}
+
+func (s *Player) measureLoudness(video *youtube.Video, data []byte) {
+	utils.BackgroundTask(s.persistWg, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		gain, err := s.loudness.Measure(ctx, video, data)
+		if err != nil {
+			return err
+		}
+		s.logger.Info("measured loudness", "video", video.ID, "gain", gain)
+		return nil
+	})
+}
+
+// encodeOptions builds the encoder options, start is skipped by the encoder and
+// normalization is the loudness filter that runs before the user's filter.
+func (s *Player) encodeOptions(start time.Duration, normalization string, filter Filter) dca.EncodeOptions {
+	options := *dca.StdEncodeOptions
+	options.RawOutput = true
+	options.Bitrate = 128
+	options.Channels = 2
+	options.Application = dca.AudioApplicationLowDelay
+	// dca only applies VolumeFloat in steps of 10%, the volume is set by
+	// the audio filter instead
+	options.VolumeFloat = 1.0
+	options.VBR = true
+	options.Threads = 0
+	options.BufferedFrames = 100
+	options.PacketLoss = 0
+	options.FrameDuration = 20
+	options.StartTime = int(start.Seconds())
+	options.AudioFilter = audioFilter(s.Volume(), normalization, filter)
+
+	return options
+}
+
+// audioFilter chains the volume in percent, the normalization and the user's
+// filter.
+func audioFilter(volume int, normalization string, filter Filter) string {
+	filters := []string{fmt.Sprintf("volume=%.2f", float64(volume)/100)}
+	if normalization != "" {
+		filters = append(filters, normalization)
+	}
+	if chain := filter.FFmpeg(); chain != "" {
+		filters = append(filters, chain)
+	}
+	return strings.Join(filters, ",")
+}
+
+// close stops the encoder and yt-dlp and waits for yt-dlp to exit.
+func (t *track) close() {
+	if t.session.Running() {
+		if err := t.session.Stop(); err != nil {
+			t.logger.Error("failed to stop encoding session", slog.String("error", err.Error()))
+		}
+	}
+	t.cancel()
+	defer t.session.Cleanup()
+
+	if t.ytdlp == nil {
+		return
+	}
+
+	// yt-dlp gets killed when the track didn't play until the end
+	err := t.ytdlp.Wait()
+	if err != nil {
+		t.logger.Debug("player", slog.String("yt-dlp exited", err.Error()))
+	}
+	_, _ = io.Copy(io.Discard, t.stdout)
+	_ = t.stdout.Close()
+
+	if err == nil && t.finished && t.capture != nil {
+		t.persist()
+	}
+}
+
+// persist measures the loudness and uploads the captured audio. Both update
+// the media record, so they run one after the other.
+func (t *track) persist() {
+	data := t.capture.Bytes()
+	if len(data) == 0 {
+		return
+	}
+
+	utils.BackgroundTask(t.wg, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		if t.loudness != nil {
+			gain, err := t.loudness.Measure(ctx, t.video, data)
+			if err != nil {
+				t.logger.Error("failed to measure loudness", slog.String("error", err.Error()))
+			} else {
+				t.logger.Info("measured loudness", "gain", gain)
+			}
+		}
+
+		if t.cache != nil {
+			if err := t.cache.Put(ctx, t.video, data); err != nil {
+				return err
+			}
+			t.logger.Info("cached audio", "size", len(data))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package playback
+
+import "testing"
+
+func TestAudioFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		volume        int
+		normalization string
+		filter        Filter
+		want          string
+	}{
+		{name: "full volume", volume: 100, want: "volume=1.00"},
+		{name: "between steps", volume: 55, want: "volume=0.55"},
+		{name: "quiet", volume: 3, want: "volume=0.03"},
+		{name: "muted", volume: 0, want: "volume=0.00"},
+		{
+			name:          "normalization and filter",
+			volume:        80,
+			normalization: "volume=-3.50dB",
+			filter:        Filter{Extra: "apulsator=hz=0.125"},
+			want:          "volume=0.80,volume=-3.50dB,apulsator=hz=0.125",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audioFilter(tt.volume, tt.normalization, tt.filter); got != tt.want {
+				t.Errorf("audioFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package playback
+
+// VoteSkip records the user's vote to skip the current video and returns the
+// number of votes. The video gets skipped once needed votes are reached, votes
+// reset with every new video.
+func (s *Player) VoteSkip(userID string, needed int) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.skippableLocked() {
+		return 0, false, ErrSkipNotPossible
+	}
+
+	if s.skipVotes == nil {
+		s.skipVotes = map[string]struct{}{}
+	}
+	s.skipVotes[userID] = struct{}{}
+	votes := len(s.skipVotes)
+	if votes < needed {
+		return votes, false, nil
+	}
+
+	if err := s.skipLocked(1); err != nil {
+		return votes, false, err
+	}
+	return votes, true, nil
+}
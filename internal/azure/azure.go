@@ -70,6 +70,27 @@ func (a *AzureClient) CreateContainer(ctx context.Context) (*azcosmos.ContainerC
 	return client.NewContainer("video")
 }
 
+// CreateSettingsContainer creates the container of the guild settings. All
+// settings share one partition, so they can be listed at startup.
+func (a *AzureClient) CreateSettingsContainer(ctx context.Context) (*azcosmos.ContainerClient, error) {
+	properties := azcosmos.ContainerProperties{
+		ID: "settings",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
+			Paths: []string{"/kind"},
+		},
+	}
+
+	client, err := a.azcosmos.NewDatabase("data")
+	if err != nil {
+		return nil, err
+	}
+
+	// fails if the container already exists
+	_, _ = client.CreateContainer(ctx, properties, nil)
+
+	return client.NewContainer("settings")
+}
+
 func (a *AzureClient) NewAzBlobStorage(connectionString string) {
 	client, err := azblob.NewClientFromConnectionString(connectionString, nil)
 	if err != nil {
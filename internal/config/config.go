@@ -10,6 +10,9 @@ type Config struct {
 	AzureClientID                    string `env:"AZURE_CLIENT_ID,required"`
 	AzureCosmosURL                   string `env:"AZURE_COSMOS_URL,required"`
 	AzureBlobStorageConnectionString string `env:"AZURE_BLOB_STORAGE_CONNECTION_STRING,required"`
+	AudioCacheContainer              string `env:"AUDIO_CACHE_CONTAINER" envDefault:"audio"`
+	AudioCacheMaxSizeMB              int64  `env:"AUDIO_CACHE_MAX_SIZE_MB" envDefault:"2048"`
+	VoiceReconnectAttempts           int    `env:"VOICE_RECONNECT_ATTEMPTS" envDefault:"5"`
 }
 
 func New() (*Config, error) {
@@ -39,3 +42,15 @@ func (c *Config) GetAzureCosmosURL() string {
 func (c *Config) GetAzureBlobStorageConnectionString() string {
 	return c.AzureBlobStorageConnectionString
 }
+
+func (c *Config) GetAudioCacheContainer() string {
+	return c.AudioCacheContainer
+}
+
+func (c *Config) GetAudioCacheMaxSize() int64 {
+	return c.AudioCacheMaxSizeMB << 20
+}
+
+func (c *Config) GetVoiceReconnectAttempts() int {
+	return c.VoiceReconnectAttempts
+}
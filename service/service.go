@@ -5,6 +5,7 @@ import (
 	"jnelle/discord-music-bot/adapter"
 	"jnelle/discord-music-bot/adapter/azure"
 	"jnelle/discord-music-bot/app"
+	"jnelle/discord-music-bot/domain/playback"
 	db "jnelle/discord-music-bot/internal/azure"
 	"jnelle/discord-music-bot/internal/config"
 	"jnelle/discord-music-bot/internal/discord/bot"
@@ -34,7 +35,27 @@ func New(cfg config.Config, ctx context.Context) (*app.Application, error) {
 	azClient.NewAzBlobStorage(cfg.GetAzureBlobStorageConnectionString())
 	storage := azure.NewStorageRepository(azClient.GetAzBlobClient())
 	adapter := adapter.New(cacheDir, cfg.GetProxy(), cosmosDB, storage)
-	app := app.New(adapter.YouTube, bot, adapter)
+	if err := storage.CreateContainer(ctx, cfg.GetAudioCacheContainer()); err != nil {
+		slog.Error("[service.go]", "error while creating audio cache container: %v", err)
+		return nil, err
+	}
+	cache := playback.NewAudioCache(cosmosDB, storage, cfg.GetAudioCacheContainer(), cfg.GetAudioCacheMaxSize())
+	if err := cache.Load(ctx); err != nil {
+		// the cache works without, it only can't evict the earlier files
+		slog.Error("[service.go]", "error while loading audio cache: %v", err)
+	}
+	loudness := playback.NewLoudness(cosmosDB)
+	settingsContainer, err := azClient.CreateSettingsContainer(ctx)
+	if err != nil {
+		slog.Error("[service.go]", "error while opening settings container: %v", err)
+		return nil, err
+	}
+	settings := playback.NewSettingsStorage(azure.NewSettingsRepository(settingsContainer))
+	if err := settings.Load(ctx); err != nil {
+		slog.Error("[service.go]", "error while loading settings: %v", err)
+		return nil, err
+	}
+	app := app.New(adapter.YouTube, bot, adapter, cache, loudness, settings, cfg.GetVoiceReconnectAttempts())
 
 	err = bot.OpenConnection()
 	if err != nil {